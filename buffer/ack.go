@@ -0,0 +1,75 @@
+package buffer
+
+import (
+	"context"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// AckHandle is the handle of a message received with ReceiveWithAck.
+type AckHandle interface {
+	// Ack acknowledges the message so that it is never delivered again.
+	//
+	// Returns:
+	//   - error: An error if the message is no longer in flight; either because
+	//     it was already settled or because its visibility timeout has elapsed.
+	Ack() error
+
+	// Nack negatively acknowledges the message so that it is delivered again
+	// right away. If the message has failed the number of attempts set with
+	// WithMaxAttempts, it is dead-lettered instead.
+	//
+	// Returns:
+	//   - error: An error if the message is no longer in flight; either because
+	//     it was already settled or because its visibility timeout has elapsed.
+	Nack() error
+}
+
+// receiveWithAckAct is an action that receives a message from the Buffer along
+// with its ack handle.
+type receiveWithAckAct[T any] struct {
+	// msg is the destination to receive the message.
+	msg *T
+
+	// handle is the destination to receive the ack handle.
+	handle *AckHandle
+}
+
+// Run implements the common.Action interface.
+func (act *receiveWithAckAct[T]) Run(ctx context.Context) error {
+	c, err := fromContext[T](ctx)
+	if err != nil {
+		return err
+	}
+
+	msg, ack, err := c.buffer.ReceiveWithAck()
+	if err != nil {
+		return err
+	}
+
+	*act.msg = msg
+	*act.handle = ack
+
+	return nil
+}
+
+// ReceiveWithAck receives a message from a Buffer created with the WithAckMode
+// option along with the handle used to settle it. The message is delivered
+// again if it is not acknowledged within the visibility timeout.
+//
+// Parameters:
+//   - dest: The destination to receive the message.
+//   - handle: The destination to receive the ack handle.
+//
+// Returns:
+//   - common.Action: The receive action. Nil if dest or handle is nil.
+func ReceiveWithAck[T any](dest *T, handle *AckHandle) common.Action {
+	if dest == nil || handle == nil {
+		return nil
+	}
+
+	return &receiveWithAckAct[T]{
+		msg:    dest,
+		handle: handle,
+	}
+}
@@ -0,0 +1,72 @@
+package buffer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+func TestAckMode(t *testing.T) {
+	clock := common.NewManualClock(time.Unix(0, 0))
+
+	ctx, cancel := NewContext[int](context.Background(),
+		WithClock[int](clock),
+		WithAckMode[int](time.Minute),
+	)
+	defer cancel()
+
+	err := common.Run(ctx, Send(1), Send(2))
+	if err != nil {
+		t.Fatalf("could not send: %v", err)
+	}
+
+	var x int
+	var first, second AckHandle
+
+	err = common.Run(ctx, ReceiveWithAck(&x, &first))
+	if err != nil || x != 1 {
+		t.Fatalf("expected %d, got %d (%v)", 1, x, err)
+	}
+
+	err = common.Run(ctx, ReceiveWithAck(&x, &second))
+	if err != nil || x != 2 {
+		t.Fatalf("expected %d, got %d (%v)", 2, x, err)
+	}
+
+	err = second.Ack()
+	if err != nil {
+		t.Fatalf("could not ack: %v", err)
+	}
+
+	err = second.Ack()
+	if err == nil {
+		t.Fatalf("expected a settled message to not be in flight")
+	}
+
+	// The visibility timeout of the first message elapses.
+	clock.Advance(2 * time.Minute)
+
+	var third AckHandle
+
+	err = common.Run(ctx, ReceiveWithAck(&x, &third))
+	if err != nil || x != 1 {
+		t.Fatalf("expected %d to be redelivered, got %d (%v)", 1, x, err)
+	}
+
+	err = first.Ack()
+	if err == nil {
+		t.Fatalf("expected a timed out message to not be in flight")
+	}
+
+	err = third.Nack()
+	if err != nil {
+		t.Fatalf("could not nack: %v", err)
+	}
+
+	err = common.Run(ctx, Receive(&x))
+	if err != nil || x != 1 {
+		t.Fatalf("expected %d to be redelivered, got %d (%v)", 1, x, err)
+	}
+}
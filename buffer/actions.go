@@ -2,6 +2,7 @@ package buffer
 
 import (
 	"context"
+	"time"
 
 	"github.com/PlayerR9/go-safe/common"
 )
@@ -22,9 +23,9 @@ func (act *resetAct[T]) Run(ctx context.Context) error {
 }
 
 // Reset removes all elements from the Buffer, effectively resetting
-// it to an empty state. Precalculated elements are kept as they are no longer
-// in the buffer but in the channel. It locks the firstMutex to ensure
-// thread-safety during the operation.
+// it to an empty state. Messages are only handed off to a receiver that is
+// waiting for them, so none is kept in the channel. Delayed messages are not
+// removed.
 //
 // This method is safe for concurrent use by multiple goroutines.
 //
@@ -68,6 +69,92 @@ func Send[T any](msg T) common.Action {
 	}
 }
 
+// sendAtAct is an action that sends a message to the Buffer that is delivered
+// at a given time.
+type sendAtAct[T any] struct {
+	// msg is the message to send.
+	msg T
+
+	// at is the time at which the message is delivered.
+	at time.Time
+}
+
+// Run implements the common.Action interface.
+func (act *sendAtAct[T]) Run(ctx context.Context) error {
+	c, err := fromContext[T](ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.buffer.SendAt(act.msg, act.at)
+}
+
+// SendAt sends a message to the Buffer that stays invisible to Receive until
+// the given time. The Buffer wakes up exactly when the message becomes due,
+// according to the clock set with WithClock. The time-to-live set with WithTTL
+// only starts once the message becomes visible.
+//
+// If the Buffer is closed before the message becomes visible, it is never
+// handed out and is dead-lettered instead; use Drain before closing to move it
+// to another Buffer.
+//
+// Parameters:
+//   - msg: The message to send.
+//   - at: The time at which the message becomes visible. If it is not in the
+//     future, the message is visible right away.
+//
+// Returns:
+//   - common.Action: The send action. Never returns nil.
+func SendAt[T any](msg T, at time.Time) common.Action {
+	return &sendAtAct[T]{
+		msg: msg,
+		at:  at,
+	}
+}
+
+// sendAfterAct is an action that sends a message to the Buffer that is
+// delivered after a delay.
+type sendAfterAct[T any] struct {
+	// msg is the message to send.
+	msg T
+
+	// delay is the delay after which the message is delivered.
+	delay time.Duration
+}
+
+// Run implements the common.Action interface.
+func (act *sendAfterAct[T]) Run(ctx context.Context) error {
+	c, err := fromContext[T](ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.buffer.SendAfter(act.msg, act.delay)
+}
+
+// SendAfter sends a message to the Buffer that stays invisible to Receive until
+// the given delay has elapsed. The delay is measured with the clock set with
+// WithClock. The time-to-live set with WithTTL only starts once the message
+// becomes visible.
+//
+// If the Buffer is closed before the message becomes visible, it is never
+// handed out and is dead-lettered instead; use Drain before closing to move it
+// to another Buffer.
+//
+// Parameters:
+//   - msg: The message to send.
+//   - delay: The delay after which the message becomes visible. If it is not
+//     positive, the message is visible right away.
+//
+// Returns:
+//   - common.Action: The send action. Never returns nil.
+func SendAfter[T any](msg T, delay time.Duration) common.Action {
+	return &sendAfterAct[T]{
+		msg:   msg,
+		delay: delay,
+	}
+}
+
 // receiveAct is an action that receives a message from the Buffer.
 type receiveAct[T any] struct {
 	// msg is the destination to receive the message.
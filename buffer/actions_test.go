@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/PlayerR9/go-safe/common"
 )
@@ -105,3 +106,87 @@ func TestTrimFrom(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestSendAfter(t *testing.T) {
+	clock := common.NewManualClock(time.Unix(0, 0))
+
+	ctx, cancel := NewContext[int](context.Background(), WithClock[int](clock))
+	defer cancel()
+
+	err := common.Run(ctx, SendAfter(1, time.Minute), SendAt(2, clock.Now().Add(time.Second)), Send(3))
+	if err != nil {
+		t.Fatalf("could not send: %v", err)
+	}
+
+	steps := []struct {
+		advance  time.Duration
+		expected int
+	}{
+		{advance: 0, expected: 3},
+		{advance: time.Second, expected: 2},
+		{advance: time.Minute, expected: 1},
+	}
+
+	for _, step := range steps {
+		clock.Advance(step.advance)
+
+		var x int
+
+		err := common.Run(ctx, Receive(&x))
+		if err != nil {
+			t.Fatalf("could not receive: %v", err)
+		} else if x != step.expected {
+			t.Fatalf("expected %d, got %d", step.expected, x)
+		}
+	}
+}
+
+func TestSendAfterClose(t *testing.T) {
+	clock := common.NewManualClock(time.Unix(0, 0))
+
+	ctx, cancel := NewContext[int](context.Background(),
+		WithClock[int](clock),
+		WithDeadLetters[int](),
+	)
+
+	dl, err := DeadLetters[int](ctx)
+	if err != nil {
+		t.Fatalf("could not get the dead letters: %v", err)
+	}
+
+	err = common.Run(ctx, SendAfter(1, time.Minute), Send(2))
+	if err != nil {
+		t.Fatalf("could not send: %v", err)
+	}
+
+	var x int
+
+	err = common.Run(ctx, Receive(&x))
+	if err != nil {
+		t.Fatalf("could not receive: %v", err)
+	} else if x != 2 {
+		t.Fatalf("expected %d, got %d", 2, x)
+	}
+
+	result := make(chan error)
+
+	go func() {
+		var x int
+
+		err := common.Run(ctx, Receive(&x))
+		if err == nil {
+			t.Errorf("expected no message once closed, got %d", x)
+		}
+
+		result <- err
+	}()
+
+	cancel()
+
+	<-result
+
+	x, ok := dl.Receive()
+	if !ok || x != 1 {
+		t.Fatalf("expected the delayed message %d to be dead-lettered, got %d", 1, x)
+	}
+}
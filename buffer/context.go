@@ -3,19 +3,25 @@ package buffer
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	internal "github.com/PlayerR9/go-safe/buffer/internal"
+	"github.com/PlayerR9/go-safe/buffer/wal"
 	"github.com/PlayerR9/go-safe/common"
 )
 
-type contextKey struct{}
+// contextKey is the key of the Context of type T in a context.Context. It is
+// generic so that a context can carry one buffer per type of message.
+type contextKey[T any] struct{}
 
 func fromContext[T any](ctx context.Context) (*Context[T], error) {
 	if ctx == nil {
 		return nil, common.NewErrNilParam("ctx")
 	}
 
-	v, ok := ctx.Value(contextKey{}).(*Context[T])
+	v, ok := ctx.Value(contextKey[T]{}).(*Context[T])
 	if !ok || v == nil {
 		return nil, errors.New("expected non-nil *Context[T] in context")
 	}
@@ -23,33 +29,391 @@ func fromContext[T any](ctx context.Context) (*Context[T], error) {
 	return v, nil
 }
 
+// Mode is the ownership mode of the buffer of a nested Context.
+type Mode int
+
+const (
+	// Shared makes the nested Context use the buffer of its parent. The buffer
+	// is reference counted and only the last cancel closes it.
+	Shared Mode = iota
+
+	// Isolated gives the nested Context its own buffer. When the nested Context
+	// is cancelled, the messages that are still pending in its buffer are
+	// forwarded to the buffer of its parent.
+	Isolated
+)
+
+// config is the configuration of a Context.
+type config[T any] struct {
+	// mode is the ownership mode used when the Context is nested.
+	mode Mode
+
+	// cmp is the comparator of the messages of a priority buffer.
+	cmp func(a, b T) int
+
+	// clock is the clock used to schedule delayed messages.
+	clock common.Clock
+
+	// ttl is the default time-to-live of the messages.
+	ttl time.Duration
+
+	// maxAttempts is the number of failed attempts after which a message is
+	// dead-lettered.
+	maxAttempts int
+
+	// deadLetters is true if the dead letters are kept.
+	deadLetters bool
+
+	// visibility is the visibility timeout of the ack mode.
+	visibility time.Duration
+
+	// log is the write-ahead log of a durable buffer.
+	log *wal.Log[T]
+
+	// name is the name under which the statistics of the buffer are exported.
+	name string
+}
+
+// Option is an option of NewContext.
+type Option[T any] func(cfg *config[T])
+
+// WithMode sets the ownership mode used when the new Context is nested inside
+// another Context of the same type. Shared is used by default.
+//
+// Parameters:
+//   - mode: The ownership mode.
+//
+// Returns:
+//   - Option[T]: The option. Never returns nil.
+func WithMode[T any](mode Mode) Option[T] {
+	return func(cfg *config[T]) {
+		cfg.mode = mode
+	}
+}
+
+// WithPriority makes the buffer hand out the pending message with the highest
+// priority first instead of the oldest one. Messages with the same priority are
+// still handed out in FIFO order.
+//
+// It has no effect on a Shared nested context, as it uses the buffer of its
+// parent.
+//
+// Parameters:
+//   - cmp: The comparator of the messages. A positive result means that a has a
+//     higher priority than b. If nil, the buffer is a FIFO buffer.
+//
+// Returns:
+//   - Option[T]: The option. Never returns nil.
+func WithPriority[T any](cmp func(a, b T) int) Option[T] {
+	return func(cfg *config[T]) {
+		cfg.cmp = cmp
+	}
+}
+
+// WithClock sets the clock used by the buffer to schedule the messages sent
+// with SendAfter and SendAt. It is meant to inject a common.ManualClock in
+// tests.
+//
+// It has no effect on a Shared nested context, as it uses the buffer of its
+// parent.
+//
+// Parameters:
+//   - clock: The clock to use. If nil, common.RealClock is used.
+//
+// Returns:
+//   - Option[T]: The option. Never returns nil.
+func WithClock[T any](clock common.Clock) Option[T] {
+	return func(cfg *config[T]) {
+		cfg.clock = clock
+	}
+}
+
+// WithTTL sets the default time-to-live of the messages sent to the buffer.
+// Expired messages are never handed out by Receive; they are dead-lettered
+// instead.
+//
+// It has no effect on a Shared nested context, as it uses the buffer of its
+// parent.
+//
+// Parameters:
+//   - ttl: The time-to-live. If it is not positive, the messages never expire.
+//
+// Returns:
+//   - Option[T]: The option. Never returns nil.
+func WithTTL[T any](ttl time.Duration) Option[T] {
+	return func(cfg *config[T]) {
+		cfg.ttl = ttl
+	}
+}
+
+// WithMaxAttempts sets the number of times the processing of a message can
+// fail in Process before the message is dead-lettered.
+//
+// It has no effect on a Shared nested context, as it uses the buffer of its
+// parent.
+//
+// Parameters:
+//   - n: The number of attempts. If it is not positive, the messages are
+//     retried forever.
+//
+// Returns:
+//   - Option[T]: The option. Never returns nil.
+func WithMaxAttempts[T any](n int) Option[T] {
+	return func(cfg *config[T]) {
+		cfg.maxAttempts = n
+	}
+}
+
+// WithDeadLetters makes the buffer keep its dead letters so that they can be
+// received from the receiver returned by DeadLetters. Otherwise, dead letters
+// are discarded and only counted.
+//
+// It has no effect on a Shared nested context, as it uses the buffer of its
+// parent.
+//
+// Returns:
+//   - Option[T]: The option. Never returns nil.
+func WithDeadLetters[T any]() Option[T] {
+	return func(cfg *config[T]) {
+		cfg.deadLetters = true
+	}
+}
+
+// WithAckMode puts the buffer in ack mode, which provides at-least-once
+// delivery: a message received with ReceiveWithAck stays in flight until it is
+// settled through its AckHandle. If it is not acknowledged within the
+// visibility timeout, or if it is negatively acknowledged, it is delivered
+// again.
+//
+// It has no effect on a Shared nested context, as it uses the buffer of its
+// parent.
+//
+// Parameters:
+//   - visibilityTimeout: The visibility timeout. If it is not positive, ack
+//     mode is disabled.
+//
+// Returns:
+//   - Option[T]: The option. Never returns nil.
+func WithAckMode[T any](visibilityTimeout time.Duration) Option[T] {
+	return func(cfg *config[T]) {
+		cfg.visibility = visibilityTimeout
+	}
+}
+
+// WithWAL makes the buffer durable. Every message sent is appended to the
+// write-ahead log until it is acknowledged, and the messages that were still
+// pending when the log was opened are replayed into the buffer. Only the first
+// buffer built on the log replays them. A message is
+// acknowledged once it is received with Receive, acknowledged through its
+// AckHandle, processed successfully, dead-lettered or reset.
+//
+// Replayed messages are visible right away and never expire. The log is not
+// closed when the buffer is released.
+//
+// It has no effect on a Shared nested context, as it uses the buffer of its
+// parent.
+//
+// Parameters:
+//   - log: The write-ahead log opened with wal.Open. If nil, the buffer is not
+//     durable.
+//
+// Returns:
+//   - Option[T]: The option. Never returns nil.
+func WithWAL[T any](log *wal.Log[T]) Option[T] {
+	return func(cfg *config[T]) {
+		cfg.log = log
+	}
+}
+
+// WithName names the buffer so that its statistics are exported by AllStats,
+// PublishExpvar and WritePrometheus until it is closed. A buffer replaces any
+// open buffer with the same name.
+//
+// It has no effect on a Shared nested context, as it uses the buffer of its
+// parent.
+//
+// Parameters:
+//   - name: The name of the buffer. If empty, the statistics of the buffer are
+//     not exported.
+//
+// Returns:
+//   - Option[T]: The option. Never returns nil.
+func WithName[T any](name string) Option[T] {
+	return func(cfg *config[T]) {
+		cfg.name = name
+	}
+}
+
+// Context is the value that NewContext stores in a context.Context.
 type Context[T any] struct {
+	// buffer is the buffer that messages are sent to and received from.
 	buffer *internal.Buffer[T]
+
+	// refs is the number of Contexts holding a reference to the buffer.
+	refs *atomic.Int64
+
+	// name is the name under which the statistics of the buffer are exported.
+	name string
 }
 
-func NewContext[T any](parent context.Context) (context.Context, context.CancelFunc) {
+// acquire adds a reference to the buffer of the Context.
+func (c *Context[T]) acquire() {
+	c.refs.Add(1)
+}
+
+// release removes a reference to the buffer of the Context and closes it
+// once the last reference is gone.
+func (c *Context[T]) release() {
+	if c.refs.Add(-1) != 0 {
+		return
+	}
+
+	c.buffer.Close()
+
+	if c.name != "" {
+		unregister(c.name, c.buffer)
+	}
+}
+
+// newRootContext creates a Context that owns a new buffer.
+//
+// Parameters:
+//   - cfg: The configuration of the Context.
+//
+// Returns:
+//   - *Context[T]: The new Context. Never returns nil.
+func newRootContext[T any](cfg *config[T]) *Context[T] {
+	var b *internal.Buffer[T]
+
+	if cfg.cmp == nil {
+		b = new(internal.Buffer[T])
+	} else {
+		b = internal.NewPriorityBuffer(cfg.cmp)
+	}
+
+	b.SetClock(cfg.clock)
+	b.SetTTL(cfg.ttl)
+	b.SetMaxAttempts(cfg.maxAttempts)
+	b.SetVisibilityTimeout(cfg.visibility)
+	b.SetWAL(cfg.log)
+
+	if cfg.deadLetters {
+		b.EnableDeadLetters()
+	}
+
+	c := &Context[T]{
+		buffer: b,
+		refs:   new(atomic.Int64),
+		name:   cfg.name,
+	}
+
+	err := c.buffer.Start()
+	if err != nil {
+		panic(err)
+	}
+
+	if c.name != "" {
+		register(c.name, c.buffer)
+	}
+
+	c.acquire()
+
+	return c
+}
+
+// NewContext creates a new context that carries a buffer of messages of type
+// T. The buffer is used by the Send, Receive and Reset actions.
+//
+// If the parent already carries a buffer of the same type, the new context is
+// nested and the ownership of the buffer depends on the Mode option:
+//   - Shared: Both contexts use the same buffer. It is closed by the last
+//     cancel.
+//   - Isolated: The new context gets its own buffer. When it is cancelled, the
+//     messages still pending in it are forwarded to the parent's buffer.
+//
+// The buffer is released either when the returned cancel function is called or
+// when the parent is done.
+//
+// Parameters:
+//   - parent: The parent context.
+//   - opts: The options of the context.
+//
+// Returns:
+//   - context.Context: The new context.
+//   - context.CancelFunc: The function that releases the buffer and then cancels
+//     the context. It blocks until the buffer is released.
+func NewContext[T any](parent context.Context, opts ...Option[T]) (context.Context, context.CancelFunc) {
+	var cfg config[T]
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
 	ctx, cancel := context.WithCancel(parent)
 
-	c := &Context[T]{}
+	var c *Context[T]
+	var release func()
 
 	pc, err := fromContext[T](parent)
-	if err == nil {
-		c.buffer = pc.buffer
-	} else {
-		c.buffer = new(internal.Buffer[T])
+	switch {
+	case err != nil:
+		c = newRootContext(&cfg)
+		release = c.release
+	case cfg.mode == Isolated:
+		c = newRootContext(&cfg)
+
+		pc.acquire()
+
+		release = func() {
+			defer pc.release()
+
+			done := make(chan struct{})
+
+			go func() {
+				defer close(done)
 
-		err := c.buffer.Start()
-		if err != nil {
-			panic(err)
+				for {
+					msg, err := c.buffer.Receive()
+					if err != nil {
+						return
+					}
+
+					_ = pc.buffer.Send(msg)
+				}
+			}()
+
+			c.release()
+
+			<-done
 		}
+	default:
+		c = &Context[T]{
+			buffer: pc.buffer,
+			refs:   pc.refs,
+			name:   pc.name,
+		}
+
+		c.acquire()
+
+		release = c.release
 	}
 
-	ctx = context.WithValue(parent, contextKey{}, c)
+	var once sync.Once
+
+	stop := context.AfterFunc(ctx, func() {
+		once.Do(release)
+	})
+
+	ctx = context.WithValue(ctx, contextKey[T]{}, c)
 
 	cancelFn := func() {
-		cancel()
+		stop()
 
-		c.buffer.Close()
+		once.Do(release)
+
+		cancel()
 	}
 
 	return ctx, cancelFn
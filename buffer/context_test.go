@@ -0,0 +1,141 @@
+package buffer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+func TestNestedShared(t *testing.T) {
+	parent, cancelParent := NewContext[int](context.Background())
+	child, cancelChild := NewContext[int](parent, WithMode[int](Shared))
+
+	err := common.Run(child, Send(1))
+	if err != nil {
+		t.Fatalf("could not send to child: %v", err)
+	}
+
+	var x int
+
+	err = common.Run(parent, Receive(&x))
+	if err != nil {
+		t.Fatalf("could not receive from parent: %v", err)
+	} else if x != 1 {
+		t.Fatalf("expected %d, got %d", 1, x)
+	}
+
+	cancelChild()
+
+	select {
+	case <-child.Done():
+	default:
+		t.Fatalf("expected child to be done")
+	}
+
+	err = Send(2).Run(parent)
+	if err != nil {
+		t.Fatalf("expected parent buffer to be open after child cancel: %v", err)
+	}
+
+	err = Receive(&x).Run(parent)
+	if err != nil {
+		t.Fatalf("could not receive from parent: %v", err)
+	} else if x != 2 {
+		t.Fatalf("expected %d, got %d", 2, x)
+	}
+
+	cancelParent()
+
+	err = Send(3).Run(parent)
+	if err == nil {
+		t.Fatalf("expected buffer to be closed after the last cancel")
+	}
+}
+
+func TestNestedIsolated(t *testing.T) {
+	const (
+		MaxCount int = 10
+	)
+
+	parent, cancelParent := NewContext[int](context.Background())
+	defer cancelParent()
+
+	child, cancelChild := NewContext[int](parent, WithMode[int](Isolated))
+
+	for i := 0; i < MaxCount; i++ {
+		err := common.Run(child, Send(i))
+		if err != nil {
+			t.Fatalf("could not send %d to child: %v", i, err)
+		}
+	}
+
+	cancelChild()
+
+	err := Send(MaxCount).Run(child)
+	if err == nil {
+		t.Fatalf("expected child buffer to be closed")
+	}
+
+	for i := 0; i < MaxCount; i++ {
+		var x int
+
+		err := common.Run(parent, Receive(&x))
+		if err != nil {
+			t.Fatalf("could not receive %d from parent: %v", i, err)
+		} else if x != i {
+			t.Fatalf("expected %d, got %d", i, x)
+		}
+	}
+}
+
+func TestNestedParentCancel(t *testing.T) {
+	parent, cancelParent := NewContext[int](context.Background())
+	child, cancelChild := NewContext[int](parent, WithMode[int](Isolated))
+	defer cancelChild()
+
+	cancelParent()
+
+	<-child.Done()
+}
+
+func TestPriority(t *testing.T) {
+	const MaxCount int = 10
+
+	ctx, cancel := NewContext[int](context.Background(), WithPriority(func(a, b int) int {
+		return a - b
+	}))
+	defer cancel()
+
+	for _, x := range []int{3, 9, 0, 7, 1, 8, 5, 2, 6, 4} {
+		err := Send(x).Run(ctx)
+		if err != nil {
+			t.Fatalf("could not send %d: %v", x, err)
+		}
+	}
+
+	// Wait until every message is in the queue, so that none is handed out
+	// before the ones with a higher priority arrive.
+	for {
+		stats, err := StatsOf[int](ctx)
+		if err != nil {
+			t.Fatalf("could not get the stats: %v", err)
+		} else if stats.Depth == MaxCount {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	for want := MaxCount - 1; want >= 0; want-- {
+		var x int
+
+		err := Receive(&x).Run(ctx)
+		if err != nil {
+			t.Fatalf("could not receive: %v", err)
+		} else if x != want {
+			t.Fatalf("expected %d, got %d", want, x)
+		}
+	}
+}
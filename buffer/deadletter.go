@@ -0,0 +1,132 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internal "github.com/PlayerR9/go-safe/buffer/internal"
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// Counts are the delivery counts of a buffer.
+type Counts = internal.Counts
+
+// CountsOf returns the delivery counts of the buffer carried by the context.
+//
+// Parameters:
+//   - ctx: The context created by NewContext.
+//
+// Returns:
+//   - Counts: The delivery counts.
+//   - error: An error if the context does not carry a buffer of type T.
+func CountsOf[T any](ctx context.Context) (Counts, error) {
+	c, err := fromContext[T](ctx)
+	if err != nil {
+		return Counts{}, err
+	}
+
+	return c.buffer.Counts(), nil
+}
+
+// DeadLetters returns the receiver of the dead letters of the buffer carried by
+// the context. Once the buffer is closed, the receiver hands out the remaining
+// dead letters and then reports that it is closed.
+//
+// Parameters:
+//   - ctx: The context created by NewContext with the WithDeadLetters option.
+//
+// Returns:
+//   - common.Receiver[T]: The receiver of the dead letters.
+//   - error: An error if the context does not carry a buffer of type T or if its
+//     dead letters are not kept.
+func DeadLetters[T any](ctx context.Context) (common.Receiver[T], error) {
+	c, err := fromContext[T](ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	receiver := c.buffer.DeadLetters()
+	if receiver == nil {
+		return nil, errors.New("dead letters are not enabled")
+	}
+
+	return receiver, nil
+}
+
+// sendWithTTLAct is an action that sends a message to the Buffer that expires
+// after a time-to-live.
+type sendWithTTLAct[T any] struct {
+	// msg is the message to send.
+	msg T
+
+	// ttl is the time-to-live of the message.
+	ttl time.Duration
+}
+
+// Run implements the common.Action interface.
+func (act *sendWithTTLAct[T]) Run(ctx context.Context) error {
+	c, err := fromContext[T](ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.buffer.SendWithTTL(act.msg, act.ttl)
+}
+
+// SendWithTTL sends a message to the Buffer that expires once the given
+// time-to-live has elapsed. It overrides the default time-to-live set with
+// WithTTL.
+//
+// Parameters:
+//   - msg: The message to send.
+//   - ttl: The time-to-live of the message. If it is not positive, the message
+//     never expires.
+//
+// Returns:
+//   - common.Action: The send action. Never returns nil.
+func SendWithTTL[T any](msg T, ttl time.Duration) common.Action {
+	return &sendWithTTLAct[T]{
+		msg: msg,
+		ttl: ttl,
+	}
+}
+
+// processAct is an action that receives a message from the Buffer and
+// processes it.
+type processAct[T any] struct {
+	// fn is the function that creates the action that processes the message.
+	fn func(msg T) common.Action
+}
+
+// Run implements the common.Action interface.
+func (act *processAct[T]) Run(ctx context.Context) error {
+	c, err := fromContext[T](ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.buffer.Process(func(msg T) error {
+		return common.Run(ctx, act.fn(msg))
+	})
+}
+
+// Process receives a message from the Buffer and runs the action that fn
+// creates for it. If the action fails, the message is sent back to the Buffer
+// to be retried, unless it has failed the number of attempts set with
+// WithMaxAttempts, in which case it is dead-lettered.
+//
+// Parameters:
+//   - fn: The function that creates the action that processes the message.
+//
+// Returns:
+//   - common.Action: The process action. Nil if fn is nil.
+func Process[T any](fn func(msg T) common.Action) common.Action {
+	if fn == nil {
+		return nil
+	}
+
+	return &processAct[T]{
+		fn: fn,
+	}
+}
@@ -0,0 +1,127 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// failAct is an action that always fails.
+type failAct struct{}
+
+// Run implements the common.Action interface.
+func (failAct) Run(ctx context.Context) error {
+	return errors.New("processing failed")
+}
+
+func TestDeadLetters(t *testing.T) {
+	clock := common.NewManualClock(time.Unix(0, 0))
+
+	ctx, cancel := NewContext[int](context.Background(),
+		WithClock[int](clock),
+		WithTTL[int](time.Second),
+		WithMaxAttempts[int](2),
+		WithDeadLetters[int](),
+	)
+	defer cancel()
+
+	dl, err := DeadLetters[int](ctx)
+	if err != nil {
+		t.Fatalf("could not get the dead letters: %v", err)
+	}
+
+	err = common.Run(ctx, Send(1), SendWithTTL(2, 0))
+	if err != nil {
+		t.Fatalf("could not send: %v", err)
+	}
+
+	clock.Advance(2 * time.Second)
+
+	var x int
+
+	err = common.Run(ctx, Receive(&x))
+	if err != nil {
+		t.Fatalf("could not receive: %v", err)
+	} else if x != 2 {
+		t.Fatalf("expected %d, got %d", 2, x)
+	}
+
+	x, ok := dl.Receive()
+	if !ok || x != 1 {
+		t.Fatalf("expected the expired message %d to be dead-lettered, got %d", 1, x)
+	}
+
+	err = common.Run(ctx, SendWithTTL(3, 0))
+	if err != nil {
+		t.Fatalf("could not send: %v", err)
+	}
+
+	fn := func(msg int) common.Action {
+		return failAct{}
+	}
+
+	for i := 0; i < 2; i++ {
+		err = common.Run(ctx, Process(fn))
+		if err == nil {
+			t.Fatalf("expected the processing to fail")
+		}
+	}
+
+	x, ok = dl.Receive()
+	if !ok || x != 3 {
+		t.Fatalf("expected the failed message %d to be dead-lettered, got %d", 3, x)
+	}
+
+	counts, err := CountsOf[int](ctx)
+	if err != nil {
+		t.Fatalf("could not get the counts: %v", err)
+	}
+
+	expected := Counts{
+		Delivered:    1,
+		Expired:      1,
+		DeadLettered: 2,
+	}
+
+	if counts != expected {
+		t.Fatalf("expected %+v, got %+v", expected, counts)
+	}
+}
+
+func TestDelayLongerThanTTL(t *testing.T) {
+	clock := common.NewManualClock(time.Unix(0, 0))
+
+	ctx, cancel := NewContext[int](context.Background(),
+		WithClock[int](clock),
+		WithTTL[int](time.Second),
+	)
+	defer cancel()
+
+	err := common.Run(ctx, SendAfter(1, time.Minute), SendAt(2, clock.Now().Add(2*time.Minute)))
+	if err != nil {
+		t.Fatalf("could not send: %v", err)
+	}
+
+	for i, advance := range []time.Duration{time.Minute, time.Minute} {
+		clock.Advance(advance)
+
+		var x int
+
+		err := common.Run(ctx, Receive(&x))
+		if err != nil {
+			t.Fatalf("could not receive: %v", err)
+		} else if x != i+1 {
+			t.Fatalf("expected %d, got %d", i+1, x)
+		}
+	}
+
+	counts, err := CountsOf[int](ctx)
+	if err != nil {
+		t.Fatalf("could not get the counts: %v", err)
+	} else if counts.Expired != 0 {
+		t.Fatalf("expected no expired message, got %d", counts.Expired)
+	}
+}
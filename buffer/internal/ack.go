@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// Ack is the handle of a message received with ReceiveWithAck. It is used to
+// settle the message.
+type Ack struct {
+	// settle settles the message. ok is true for an ack and false for a nack.
+	settle func(ok bool) error
+}
+
+// Ack acknowledges the message so that it is never delivered again.
+//
+// Returns:
+//   - error: An error if the message could not be acknowledged.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrNotInFlight: If the message was already settled or its visibility
+//     timeout has elapsed.
+func (a *Ack) Ack() error {
+	if a == nil {
+		return common.ErrNilReceiver
+	}
+
+	return a.settle(true)
+}
+
+// Nack negatively acknowledges the message so that it is delivered again right
+// away, unless it has failed the maximum number of attempts or the Buffer is
+// closed, in which case it is dead-lettered.
+//
+// Returns:
+//   - error: An error if the message could not be negatively acknowledged.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrNotInFlight: If the message was already settled or its visibility
+//     timeout has elapsed.
+func (a *Ack) Nack() error {
+	if a == nil {
+		return common.ErrNilReceiver
+	}
+
+	return a.settle(false)
+}
+
+// SetVisibilityTimeout puts the Buffer in ack mode. In that mode, a message
+// received with ReceiveWithAck stays in flight until it is settled. If it is
+// not acknowledged within the visibility timeout, it is delivered again. It
+// must be called before Start.
+//
+// Parameters:
+//   - timeout: The visibility timeout. If it is not positive, ack mode is
+//     disabled.
+func (b *Buffer[T]) SetVisibilityTimeout(timeout time.Duration) {
+	if b == nil {
+		return
+	}
+
+	b.visibility = timeout
+}
+
+// retry sends a message that failed to be processed back to the Buffer, unless
+// it has failed the maximum number of attempts or the Buffer is closed, in
+// which case it is dead-lettered.
+//
+// Parameters:
+//   - env: The envelope of the message.
+func (b *Buffer[T]) retry(env envelope[T]) {
+	env.attempts++
+
+	if b.maxAttempts > 0 && env.attempts >= b.maxAttempts {
+		b.deadLetter(env)
+	} else if b.send(env) != nil {
+		b.deadLetter(env)
+	}
+}
+
+// settle settles an in-flight message.
+//
+// Parameters:
+//   - lease: The identifier of the in-flight message.
+//   - ok: True to acknowledge the message, false to negatively acknowledge it.
+//
+// Returns:
+//   - error: ErrNotInFlight if the message is no longer in flight.
+func (b *Buffer[T]) settle(lease uint64, ok bool) error {
+	b.smu.Lock()
+
+	env, found := b.inFlight[lease]
+	if found {
+		delete(b.inFlight, lease)
+	}
+
+	b.smu.Unlock()
+
+	if !found {
+		return ErrNotInFlight
+	}
+
+	if ok {
+		b.deliver(env)
+	} else {
+		b.retry(env)
+	}
+
+	return nil
+}
+
+// ReceiveWithAck receives a message along with the handle used to settle it.
+// The message stays in flight until it is settled; if it is not acknowledged
+// within the visibility timeout, it is delivered again.
+//
+// Messages received with Receive are acknowledged right away.
+//
+// Returns:
+//   - T: The message.
+//   - *Ack: The handle of the message. Nil if an error occurred.
+//   - error: An error if the message could not be received.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrAckModeDisabled: If the Buffer is not in ack mode.
+//   - ErrAlreadyClosed: If the Buffer is closed normally.
+//   - any other error: The close reason if the Buffer was aborted.
+func (b *Buffer[T]) ReceiveWithAck() (T, *Ack, error) {
+	if b == nil {
+		return *new(T), nil, common.ErrNilReceiver
+	} else if b.visibility <= 0 {
+		return *new(T), nil, ErrAckModeDisabled
+	}
+
+	env, err := b.receive()
+	if err != nil {
+		return *new(T), nil, err
+	}
+
+	b.smu.Lock()
+
+	b.lease++
+	lease := b.lease
+
+	b.inFlight[lease] = env
+
+	b.push(scheduled[T]{
+		due:   b.clock.Now().Add(b.visibility),
+		lease: lease,
+	})
+
+	b.smu.Unlock()
+
+	ack := &Ack{
+		settle: func(ok bool) error {
+			return b.settle(lease, ok)
+		},
+	}
+
+	return env.value, ack, nil
+}
@@ -1,9 +1,13 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/PlayerR9/go-safe/buffer/wal"
 	"github.com/PlayerR9/go-safe/common"
 	lls "github.com/PlayerR9/go-safe/queue"
 	sbj "github.com/PlayerR9/go-safe/subject"
@@ -25,34 +29,147 @@ import (
 // Of course, a Close method is also provided to manually close the Buffer but
 // it is not necessary to call it if the send-only channel is closed.
 //
-// To create an empty Buffer, use the `b := new(Buffer[T])` constructor.
+// To create an empty Buffer, use the `b := new(Buffer[T])` constructor. To
+// create a Buffer that hands out its messages in priority order, use
+// NewPriorityBuffer instead.
 type Buffer[T any] struct {
-	// q is a pointer to the SafeQueue that stores the elements of the Buffer.
-	q *lls.Queue[T]
+	// q is the store of the elements of the Buffer.
+	q store[envelope[T]]
+
+	// qmu synchronizes the enqueuing of messages with their hand-off so that
+	// the message that is dequeued is always the one that was handed out.
+	qmu sync.Mutex
+
+	// cmp is the comparator of the messages. If nil, the messages are handed
+	// out in FIFO order.
+	cmp func(a, b T) int
 
 	// sendTo is a channel that receives messages and sends them to the Buffer.
-	sendTo chan T
+	sendTo chan envelope[T]
 
 	// receiveFrom is a channel that receives messages from the Buffer and
 	// sends them to the consumer.
-	receiveFrom chan T
+	receiveFrom chan envelope[T]
 
 	// wg is a WaitGroup that is used to wait for the goroutines to finish.
 	wg sync.WaitGroup
 
+	// mu synchronizes the senders with Close so that no message is sent on a
+	// closed channel.
+	mu sync.RWMutex
+
+	// clock is the clock used to schedule delayed messages.
+	clock common.Clock
+
+	// schedule holds the delayed messages that are not due yet.
+	schedule priorityHeap[scheduled[T]]
+
+	// seq is the sequence number of the next scheduled message.
+	seq uint64
+
+	// smu is the mutex that synchronizes the schedule and the in-flight
+	// messages.
+	smu sync.Mutex
+
+	// wake notifies the scheduler that a message was scheduled.
+	wake chan struct{}
+
+	// stopScheduler stops the scheduler once closed.
+	stopScheduler chan struct{}
+
+	// schedulerDone is closed once the scheduler has stopped.
+	schedulerDone chan struct{}
+
+	// ttl is the time-to-live of the messages sent with Send. Zero means that
+	// they never expire.
+	ttl time.Duration
+
+	// maxAttempts is the number of failed attempts after which Process
+	// dead-letters a message. Zero means that it is retried forever.
+	maxAttempts int
+
+	// deadLetters is the queue of the dead letters. Nil if dead letters are
+	// discarded.
+	deadLetters *deadLetterQueue[T]
+
+	// visibility is the visibility timeout of the in-flight messages. Zero
+	// means that ack mode is disabled.
+	visibility time.Duration
+
+	// inFlight are the messages received with ReceiveWithAck that are not
+	// settled yet, by lease. It is synchronized by smu.
+	inFlight map[uint64]envelope[T]
+
+	// lease is the identifier of the last in-flight message.
+	lease uint64
+
+	// journal is the write-ahead log of the messages. Nil if the Buffer is not
+	// durable.
+	journal *wal.Log[T]
+
+	// delivered, expired and deadLettered are the delivery counts.
+	delivered, expired, deadLettered atomic.Uint64
+
+	// stats are the live statistics of the Buffer.
+	stats metrics
+
+	// drained is closed once the goroutines of the Buffer have stopped after
+	// Close.
+	drained chan struct{}
+
+	// done is closed once the Buffer is closed.
+	done chan struct{}
+
+	// abortReason is the reason of the first abort. It is synchronized by emu.
+	abortReason error
+
+	// aborted is true once the Buffer drops its pending messages instead of
+	// handing them out. Dropped messages are not acknowledged in the
+	// write-ahead log, so they are replayed once it is reopened.
+	aborted atomic.Bool
+
+	// err is the close reason. It is set before done is closed.
+	err error
+
+	// emu is the mutex that synchronizes the abort reason.
+	emu sync.Mutex
+
+	// finishOnce ensures that the Buffer is closed only once.
+	finishOnce sync.Once
+
 	// locker is a pointer to the RWSafe that synchronizes the Buffer.
 	locker *sbj.Locker[BufferCondition]
 }
 
+// NewPriorityBuffer creates a new Buffer that hands out the pending message
+// with the highest priority first. Messages with the same priority are handed
+// out in FIFO order.
+//
+// Parameters:
+//   - cmp: The comparator of the messages. A positive result means that a has a
+//     higher priority than b. If nil, the Buffer behaves as a FIFO Buffer.
+//
+// Returns:
+//   - *Buffer[T]: The new Buffer. Never returns nil.
+func NewPriorityBuffer[T any](cmp func(a, b T) int) *Buffer[T] {
+	return &Buffer[T]{
+		cmp: cmp,
+	}
+}
+
 // listenForIncomingMessages is a method of the Buffer type that listens for
 // incoming messages from the receiveChannel and enqueues them in the Buffer.
 //
 // It must be run in a separate goroutine to avoid blocking the main thread.
-func (b *Buffer[T]) listenForIncomingMessages() {
+//
+// Parameters:
+//   - sendTo: The channel to listen to. It is passed as a parameter as Close
+//     may reset the field before the goroutine starts.
+func (b *Buffer[T]) listenForIncomingMessages(sendTo <-chan envelope[T]) {
 	defer b.wg.Done()
 
-	for msg := range b.sendTo {
-		_ = b.q.Enqueue(msg)
+	for env := range sendTo {
+		b.enqueue(env)
 	}
 
 	_ = b.locker.ChangeValue(IsRunning, false)
@@ -108,28 +225,59 @@ func (b *Buffer[T]) sendMessagesFromBuffer() {
 	}
 
 	b.locker = nil
+
+	b.qmu.Lock()
 	b.q = nil
+	b.qmu.Unlock()
 }
 
 // sendSingleMessage is a method of the Buffer type that sends a single message
-// from the Buffer to the send channel.
+// from the Buffer to the send channel. Expired messages are dead-lettered
+// instead of being sent, and every message is dropped once the Buffer is
+// aborted.
 //
 // Returns:
 //   - bool: A boolean indicating if the queue is empty.
 //   - bool: A boolean indicating if a message was sent successfully.
 func (b *Buffer[T]) sendSingleMessage() (bool, bool) {
-	msg, err := b.q.Peek()
+	b.qmu.Lock()
+	defer b.qmu.Unlock()
+
+	env, err := b.q.Peek()
 	if err != nil {
 		return true, true
 	}
 
+	if b.aborted.Load() {
+		_, err := b.q.Dequeue()
+		if err != nil {
+			return true, false
+		}
+
+		return false, true
+	}
+
+	if env.isExpired(b.clock.Now()) {
+		_, err := b.q.Dequeue()
+		if err != nil {
+			return true, false
+		}
+
+		b.expired.Add(1)
+		b.deadLetter(env)
+
+		return false, true
+	}
+
 	select {
-	case b.receiveFrom <- msg:
+	case b.receiveFrom <- env:
 		_, err := b.q.Dequeue()
 		if err != nil {
 			return true, false
 		}
 
+		b.stats.onDequeue(max(b.clock.Now().Sub(env.enqueuedAt), 0))
+
 		return false, true
 	default:
 		return false, false
@@ -149,9 +297,17 @@ func (b *Buffer[T]) Start() error {
 	b.locker.SetSubject(IsEmpty, true, true)
 	b.locker.SetSubject(IsRunning, true, true)
 
-	b.q = new(lls.Queue[T])
+	if b.cmp == nil {
+		b.q = new(lls.Queue[envelope[T]])
+	} else {
+		b.q = newPriorityQueue(func(x, y envelope[T]) int {
+			return b.cmp(x.value, y.value)
+		})
+	}
+
+	err := b.q.ObserveSizeSync(func(val int) error {
+		b.stats.onSize(val)
 
-	err := b.q.ObserveSize(func(val int) error {
 		err := b.locker.ChangeValue(IsEmpty, val == 0)
 		return err
 	})
@@ -159,44 +315,139 @@ func (b *Buffer[T]) Start() error {
 		return err
 	}
 
-	b.sendTo = make(chan T)
-	b.receiveFrom = make(chan T)
+	if b.clock == nil {
+		b.clock = common.RealClock
+	}
+
+	if b.journal != nil {
+		for _, rec := range b.journal.Pending() {
+			b.enqueue(envelope[T]{
+				value: rec.Msg,
+				seq:   rec.Seq,
+			})
+		}
+	}
+
+	b.schedule = newSchedule[T]()
+	b.inFlight = make(map[uint64]envelope[T])
+	b.wake = make(chan struct{}, 1)
+	b.stopScheduler = make(chan struct{})
+	b.schedulerDone = make(chan struct{})
+
+	b.sendTo = make(chan envelope[T])
+	b.receiveFrom = make(chan envelope[T])
+	b.drained = make(chan struct{})
+	b.done = make(chan struct{})
 
 	b.wg.Add(2)
 
-	go b.listenForIncomingMessages()
+	go b.runScheduler(b.stopScheduler, b.schedulerDone)
+	go b.listenForIncomingMessages(b.sendTo)
 	go b.sendMessagesFromBuffer()
 
 	return nil
 }
 
 // Close implements the Runner interface.
+//
+// It blocks until every pending message is received. Use CloseWithContext to
+// bound the wait.
 func (b *Buffer[T]) Close() {
-	if b == nil || b.sendTo == nil {
+	if b == nil {
 		return
 	}
 
-	close(b.sendTo)
-	b.sendTo = nil
-
-	b.wg.Wait()
-
-	close(b.receiveFrom)
-	b.receiveFrom = nil
+	_ = b.CloseWithContext(context.Background())
 }
 
 // Reset removes all elements from the Buffer, effectively resetting
-// it to an empty state. Precalculated elements are kept as they are no longer
-// in the buffer but in the channel. It locks the firstMutex to ensure
-// thread-safety during the operation.
+// it to an empty state. Messages are only handed off to a receiver that is
+// waiting for them, so none is kept in the channel. Delayed messages are not
+// removed.
 //
 // This method is safe for concurrent use by multiple goroutines.
 func (b *Buffer[T]) Reset() {
-	if b == nil || b.q == nil {
+	if b == nil {
 		return
 	}
 
+	b.qmu.Lock()
+	defer b.qmu.Unlock()
+
+	if b.q == nil {
+		return
+	}
+
+	envs := b.q.Slice()
+
 	b.q.Reset()
+
+	for _, env := range envs {
+		b.commit(env)
+	}
+}
+
+// send sends an envelope to the Buffer.
+//
+// Parameters:
+//   - env: The envelope to send.
+//
+// Returns:
+//   - error: ErrAlreadyClosed if the Buffer is closed.
+func (b *Buffer[T]) send(env envelope[T]) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.sendTo == nil {
+		return ErrAlreadyClosed
+	}
+
+	if env.seq == 0 {
+		err := b.log(&env)
+		if err != nil {
+			return err
+		}
+	}
+
+	select {
+	case b.sendTo <- env:
+	default:
+		start := time.Now()
+
+		b.sendTo <- env
+
+		b.stats.blocked.Add(int64(time.Since(start)))
+	}
+
+	return nil
+}
+
+// wrap puts a message in an envelope.
+//
+// Parameters:
+//   - msg: The message.
+//   - ttl: The time-to-live of the message. Zero means that it never expires.
+//   - visibleAt: The time at which the message becomes visible, which is when
+//     its time-to-live starts. If it is in the past, the time-to-live starts
+//     now.
+//
+// Returns:
+//   - envelope[T]: The envelope.
+func (b *Buffer[T]) wrap(msg T, ttl time.Duration, visibleAt time.Time) envelope[T] {
+	env := envelope[T]{
+		value: msg,
+	}
+
+	if ttl > 0 {
+		start := b.clock.Now()
+		if visibleAt.After(start) {
+			start = visibleAt
+		}
+
+		env.expiresAt = start.Add(ttl)
+	}
+
+	return env
 }
 
 // Send implements the Sender interface.
@@ -205,27 +456,105 @@ func (b *Buffer[T]) Send(msg T) error {
 		return common.ErrNilReceiver
 	}
 
-	if b.sendTo == nil {
+	return b.SendWithTTL(msg, b.ttl)
+}
+
+// SendWithTTL sends a message that expires once the given time-to-live has
+// elapsed. An expired message is never handed out by Receive; it is
+// dead-lettered instead.
+//
+// Parameters:
+//   - msg: The message to send.
+//   - ttl: The time-to-live of the message. If it is not positive, the message
+//     never expires.
+//
+// Returns:
+//   - error: An error if the message could not be sent.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrAlreadyClosed: If the Buffer is closed.
+func (b *Buffer[T]) SendWithTTL(msg T, ttl time.Duration) error {
+	if b == nil {
+		return common.ErrNilReceiver
+	} else if b.clock == nil {
 		return ErrAlreadyClosed
 	}
 
-	b.sendTo <- msg
+	return b.send(b.wrap(msg, ttl, time.Time{}))
+}
 
-	return nil
+// receive receives an envelope from the Buffer.
+//
+// Returns:
+//   - envelope[T]: The envelope.
+//   - error: The close reason if the Buffer is closed.
+func (b *Buffer[T]) receive() (envelope[T], error) {
+	if b.receiveFrom == nil {
+		return envelope[T]{}, ErrAlreadyClosed
+	}
+
+	env, ok := <-b.receiveFrom
+	if !ok {
+		// The close reason is set before receiveFrom is closed.
+		return envelope[T]{}, b.err
+	}
+
+	return env, nil
 }
 
-// Receive implements the Receiver interface.
-func (b *Buffer[T]) Receive() (T, error) {
+// ReceiveWithContext receives a message like Receive, but gives up once the
+// context is done.
+//
+// Parameters:
+//   - ctx: The context that bounds the wait.
+//
+// Returns:
+//   - T: The message.
+//   - error: An error if no message was received.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If ctx is nil.
+//   - ErrAlreadyClosed: If the Buffer is closed normally.
+//   - context.Canceled, context.DeadlineExceeded: If the context is done first.
+//   - any other error: The close reason if the Buffer was aborted.
+func (b *Buffer[T]) ReceiveWithContext(ctx context.Context) (T, error) {
 	if b == nil {
 		return *new(T), common.ErrNilReceiver
+	} else if ctx == nil {
+		return *new(T), common.NewErrNilParam("ctx")
 	} else if b.receiveFrom == nil {
 		return *new(T), ErrAlreadyClosed
 	}
 
-	msg, ok := <-b.receiveFrom
-	if !ok {
-		return *new(T), ErrAlreadyClosed
+	select {
+	case env, ok := <-b.receiveFrom:
+		if !ok {
+			// The close reason is set before receiveFrom is closed.
+			return *new(T), b.err
+		}
+
+		b.deliver(env)
+
+		return env.value, nil
+	case <-ctx.Done():
+		return *new(T), ctx.Err()
+	}
+}
+
+// Receive implements the Receiver interface.
+func (b *Buffer[T]) Receive() (T, error) {
+	if b == nil {
+		return *new(T), common.ErrNilReceiver
 	}
 
-	return msg, nil
+	env, err := b.receive()
+	if err != nil {
+		return *new(T), err
+	}
+
+	b.deliver(env)
+
+	return env.value, nil
 }
@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// beginClose stops accepting messages and starts draining the pending ones.
+// Does nothing if the Buffer is already closing.
+func (b *Buffer[T]) beginClose() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.sendTo == nil {
+		return
+	}
+
+	close(b.stopScheduler)
+	<-b.schedulerDone
+
+	close(b.sendTo)
+	b.sendTo = nil
+
+	go func() {
+		b.wg.Wait()
+		close(b.drained)
+	}()
+}
+
+// finish closes the Buffer once its goroutines have stopped. Only the first
+// call has an effect.
+func (b *Buffer[T]) finish() {
+	b.finishOnce.Do(func() {
+		b.emu.Lock()
+		reason := b.abortReason
+		b.emu.Unlock()
+
+		if reason == nil {
+			reason = ErrAlreadyClosed
+		}
+
+		b.err = reason
+
+		close(b.receiveFrom)
+
+		if b.deadLetters != nil {
+			b.deadLetters.close()
+		}
+
+		close(b.done)
+	})
+}
+
+// abort records the close reason, if none was recorded yet, and makes the
+// Buffer drop its pending messages instead of handing them out.
+//
+// Parameters:
+//   - reason: The close reason.
+func (b *Buffer[T]) abort(reason error) {
+	b.emu.Lock()
+
+	if b.abortReason == nil {
+		b.abortReason = reason
+	}
+
+	b.emu.Unlock()
+
+	b.aborted.Store(true)
+}
+
+// CloseWithContext closes the Buffer and waits for the pending messages to be
+// received. If the context is done first, the remaining messages are dropped
+// and the Buffer is closed with the error of the context as close reason.
+//
+// Calling it on a Buffer that is already closing waits for that close to end,
+// still bounded by the context.
+//
+// Parameters:
+//   - ctx: The context that bounds the drain.
+//
+// Returns:
+//   - error: The error of the context if the drain did not complete, nil
+//     otherwise.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - context.Canceled, context.DeadlineExceeded: If the context is done before
+//     every pending message is received.
+func (b *Buffer[T]) CloseWithContext(ctx context.Context) error {
+	if b == nil {
+		return common.ErrNilReceiver
+	} else if b.drained == nil {
+		// never started
+		return nil
+	}
+
+	b.beginClose()
+
+	var err error
+
+	select {
+	case <-b.drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+
+		b.abort(err)
+
+		<-b.drained
+	}
+
+	b.finish()
+
+	return err
+}
+
+// Abort closes the Buffer right away: the pending messages are dropped, and
+// the receivers get the reason as error instead of ErrAlreadyClosed. Messages
+// that are already being handed out are still received.
+//
+// Dropped messages are not acknowledged in the write-ahead log, so they are
+// replayed once it is reopened.
+//
+// Parameters:
+//   - reason: The close reason. If nil, ErrAborted is used.
+func (b *Buffer[T]) Abort(reason error) {
+	if b == nil || b.drained == nil {
+		return
+	}
+
+	if reason == nil {
+		reason = ErrAborted
+	}
+
+	b.beginClose()
+	b.abort(reason)
+
+	<-b.drained
+
+	b.finish()
+}
+
+// Done returns a channel that is closed once the Buffer is closed.
+//
+// Returns:
+//   - <-chan struct{}: The channel. Nil if the receiver is nil or the Buffer
+//     was never started.
+func (b *Buffer[T]) Done() <-chan struct{} {
+	if b == nil {
+		return nil
+	}
+
+	return b.done
+}
+
+// Err returns the close reason of the Buffer.
+//
+// Returns:
+//   - error: Nil if the Buffer is not closed yet, ErrAlreadyClosed if it was
+//     closed normally, or the close reason otherwise.
+func (b *Buffer[T]) Err() error {
+	if b == nil || b.done == nil {
+		return nil
+	}
+
+	select {
+	case <-b.done:
+		return b.err
+	default:
+		return nil
+	}
+}
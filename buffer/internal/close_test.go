@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCloseWithContext(t *testing.T) {
+	const (
+		MaxCount int = 3
+	)
+
+	b := new(Buffer[int])
+
+	err := b.Start()
+	if err != nil {
+		t.Fatalf("could not start: %v", err)
+	}
+
+	for i := 0; i < MaxCount; i++ {
+		err := b.Send(i)
+		if err != nil {
+			t.Fatalf("could not send %d: %v", i, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Nobody receives, so the drain cannot complete.
+	err = b.CloseWithContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	select {
+	case <-b.Done():
+	default:
+		t.Fatalf("expected buffer to be done")
+	}
+
+	_, err = b.Receive()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	} else if !errors.Is(b.Err(), context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, b.Err())
+	}
+}
+
+func TestAbort(t *testing.T) {
+	reason := errors.New("shutting down")
+
+	b := new(Buffer[int])
+
+	err := b.Start()
+	if err != nil {
+		t.Fatalf("could not start: %v", err)
+	}
+
+	if b.Err() != nil {
+		t.Fatalf("expected no close reason, got %v", b.Err())
+	}
+
+	result := make(chan error)
+
+	go func() {
+		_, err := b.Receive()
+		result <- err
+	}()
+
+	b.Abort(reason)
+
+	err = <-result
+	if err != reason {
+		t.Fatalf("expected %v, got %v", reason, err)
+	}
+
+	b = new(Buffer[int])
+
+	err = b.Start()
+	if err != nil {
+		t.Fatalf("could not start: %v", err)
+	}
+
+	b.Close()
+
+	if b.Err() != ErrAlreadyClosed {
+		t.Fatalf("expected %v, got %v", ErrAlreadyClosed, b.Err())
+	}
+}
+
+func TestResetWhileClosing(t *testing.T) {
+	const (
+		MaxCount int = 10
+	)
+
+	for range 10 {
+		b := new(Buffer[int])
+
+		err := b.Start()
+		if err != nil {
+			t.Fatalf("could not start: %v", err)
+		}
+
+		for i := 0; i < MaxCount; i++ {
+			_ = b.Send(i)
+		}
+
+		stopped := make(chan struct{})
+
+		go func() {
+			defer close(stopped)
+
+			for {
+				b.Reset()
+
+				select {
+				case <-b.Done():
+					return
+				default:
+				}
+			}
+		}()
+
+		b.Close()
+
+		<-stopped
+
+		depth := b.Stats().Depth
+		if depth != 0 {
+			t.Fatalf("expected a depth of 0, got %d", depth)
+		}
+	}
+}
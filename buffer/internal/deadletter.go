@@ -0,0 +1,214 @@
+package internal
+
+import (
+	"sync"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// deadLetterQueue is an unbounded queue of the messages that could not be
+// delivered. It implements the common.Receiver interface.
+type deadLetterQueue[T any] struct {
+	// items are the dead letters that have not been received yet.
+	items []T
+
+	// closed is true once the Buffer that owns the queue is closed.
+	closed bool
+
+	// mu is the mutex that synchronizes the queue.
+	mu sync.Mutex
+
+	// cond is signaled when a dead letter is pushed or the queue is closed.
+	cond *sync.Cond
+}
+
+// newDeadLetterQueue creates a new deadLetterQueue.
+//
+// Returns:
+//   - *deadLetterQueue[T]: The new deadLetterQueue. Never returns nil.
+func newDeadLetterQueue[T any]() *deadLetterQueue[T] {
+	q := new(deadLetterQueue[T])
+	q.cond = sync.NewCond(&q.mu)
+
+	return q
+}
+
+// push adds a dead letter to the queue. Dead letters pushed after the queue is
+// closed are still handed out to the receivers that are not done yet.
+//
+// Parameters:
+//   - msg: The dead letter.
+func (q *deadLetterQueue[T]) push(msg T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.items = append(q.items, msg)
+	q.cond.Signal()
+}
+
+// close closes the queue. The dead letters that are still in the queue can
+// still be received.
+func (q *deadLetterQueue[T]) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// Receive implements the common.Receiver interface.
+//
+// It blocks until a dead letter is available or the queue is closed and empty.
+func (q *deadLetterQueue[T]) Receive() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.items) == 0 {
+		if q.closed {
+			return *new(T), false
+		}
+
+		q.cond.Wait()
+	}
+
+	msg := q.items[0]
+
+	q.items[0] = *new(T)
+	q.items = q.items[1:]
+
+	return msg, true
+}
+
+// SetTTL sets the time-to-live of the messages sent with Send, SendAt and
+// SendAfter. It must be called before Start.
+//
+// Parameters:
+//   - ttl: The time-to-live. If it is not positive, the messages never expire.
+func (b *Buffer[T]) SetTTL(ttl time.Duration) {
+	if b == nil {
+		return
+	}
+
+	b.ttl = ttl
+}
+
+// SetMaxAttempts sets the number of failed attempts after which Process
+// dead-letters a message. It must be called before Start.
+//
+// Parameters:
+//   - n: The number of attempts. If it is not positive, the messages are
+//     retried forever.
+func (b *Buffer[T]) SetMaxAttempts(n int) {
+	if b == nil {
+		return
+	}
+
+	b.maxAttempts = n
+}
+
+// EnableDeadLetters makes the Buffer keep its dead letters so that they can be
+// received from DeadLetters. Otherwise, they are discarded. It must be called
+// before Start.
+func (b *Buffer[T]) EnableDeadLetters() {
+	if b == nil || b.deadLetters != nil {
+		return
+	}
+
+	b.deadLetters = newDeadLetterQueue[T]()
+}
+
+// DeadLetters returns the receiver of the dead letters. Once the Buffer is
+// closed, the receiver hands out the remaining dead letters and then reports
+// that it is closed.
+//
+// Returns:
+//   - common.Receiver[T]: The receiver of the dead letters. Nil if dead letters
+//     are not enabled.
+func (b *Buffer[T]) DeadLetters() common.Receiver[T] {
+	if b == nil || b.deadLetters == nil {
+		return nil
+	}
+
+	return b.deadLetters
+}
+
+// deliver records that a message was delivered and acknowledges it in the
+// write-ahead log.
+//
+// Parameters:
+//   - env: The envelope of the delivered message.
+func (b *Buffer[T]) deliver(env envelope[T]) {
+	b.commit(env)
+
+	b.delivered.Add(1)
+}
+
+// deadLetter dead-letters a message.
+//
+// Parameters:
+//   - env: The envelope of the message to dead-letter.
+func (b *Buffer[T]) deadLetter(env envelope[T]) {
+	b.commit(env)
+
+	b.deadLettered.Add(1)
+
+	if b.deadLetters != nil {
+		b.deadLetters.push(env.value)
+	}
+}
+
+// Process receives a message and processes it. If the processing fails, the
+// message is sent back to the Buffer, unless it has failed the maximum number
+// of attempts or the Buffer is closed, in which case it is dead-lettered.
+//
+// Parameters:
+//   - fn: The function that processes the message.
+//
+// Returns:
+//   - error: An error if the message could not be received or processed.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If fn is nil.
+//   - ErrAlreadyClosed: If the Buffer is closed normally.
+//   - any other error: The close reason if the Buffer was aborted.
+//   - any other error: The error returned by fn.
+func (b *Buffer[T]) Process(fn func(msg T) error) error {
+	if b == nil {
+		return common.ErrNilReceiver
+	} else if fn == nil {
+		return common.NewErrNilParam("fn")
+	}
+
+	env, err := b.receive()
+	if err != nil {
+		return err
+	}
+
+	err = fn(env.value)
+	if err == nil {
+		b.deliver(env)
+		return nil
+	}
+
+	b.retry(env)
+
+	return err
+}
+
+// Counts returns the delivery counts of the Buffer.
+//
+// Returns:
+//   - Counts: The delivery counts. The zero value if the receiver is nil.
+func (b *Buffer[T]) Counts() Counts {
+	if b == nil {
+		return Counts{}
+	}
+
+	return Counts{
+		Delivered:    b.delivered.Load(),
+		Expired:      b.expired.Load(),
+		DeadLettered: b.deadLettered.Load(),
+	}
+}
@@ -0,0 +1,49 @@
+package internal
+
+import "time"
+
+// envelope is a message along with its delivery metadata.
+type envelope[T any] struct {
+	// value is the message.
+	value T
+
+	// expiresAt is the time from which the message is expired. The zero value
+	// means that the message never expires.
+	expiresAt time.Time
+
+	// attempts is the number of times the processing of the message failed.
+	attempts int
+
+	// enqueuedAt is the time the message last entered the queue.
+	enqueuedAt time.Time
+
+	// seq is the sequence number of the message in the write-ahead log. Zero
+	// if the message is not logged.
+	seq uint64
+}
+
+// isExpired checks whether the message is expired.
+//
+// Parameters:
+//   - now: The current time.
+//
+// Returns:
+//   - bool: True if the message is expired, false otherwise.
+func (e envelope[T]) isExpired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// Counts are the delivery counts of a Buffer.
+type Counts struct {
+	// Delivered is the number of messages delivered successfully: received
+	// with Receive, acknowledged, or processed without error. A message whose
+	// processing fails is not counted, even if it is handed out again.
+	Delivered uint64
+
+	// Expired is the number of messages that expired before being handed out.
+	Expired uint64
+
+	// DeadLettered is the number of messages that were dead-lettered, either
+	// because they expired or because their processing failed too many times.
+	DeadLettered uint64
+}
@@ -8,8 +8,43 @@ var (
 	// Format:
 	//   "buffer is already closed"
 	ErrAlreadyClosed error
+
+	// ErrNotInFlight occurs when a message is acknowledged but it is no longer
+	// in flight; either because it was already settled or because its
+	// visibility timeout has elapsed.
+	//
+	// Format:
+	//   "message is not in flight"
+	ErrNotInFlight error
+
+	// ErrAckModeDisabled occurs when a message is received with an ack handle
+	// from a Buffer that is not in ack mode.
+	//
+	// Format:
+	//   "ack mode is not enabled"
+	ErrAckModeDisabled error
+
+	// ErrBadSnapshot occurs when a snapshot is malformed.
+	//
+	// Format:
+	//   "snapshot is malformed"
+	ErrBadSnapshot error
+
+	// ErrAborted occurs when a buffer is aborted without a reason.
+	//
+	// Format:
+	//   "buffer was aborted"
+	ErrAborted error
 )
 
 func init() {
 	ErrAlreadyClosed = errors.New("buffer is already closed")
+
+	ErrNotInFlight = errors.New("message is not in flight")
+
+	ErrAckModeDisabled = errors.New("ack mode is not enabled")
+
+	ErrBadSnapshot = errors.New("snapshot is malformed")
+
+	ErrAborted = errors.New("buffer was aborted")
 }
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"github.com/PlayerR9/go-safe/buffer/wal"
+)
+
+// SetWAL makes the Buffer durable: every message sent is appended to the
+// write-ahead log until it is acknowledged, and the messages that the log
+// reports as pending are replayed when the Buffer starts. It must be called
+// before Start.
+//
+// A message is acknowledged once it is received with Receive, acknowledged
+// through its Ack handle, processed successfully, dead-lettered or reset.
+// Replayed messages are visible right away and never expire.
+//
+// The Buffer does not close the log.
+//
+// Parameters:
+//   - log: The write-ahead log. If nil, the Buffer is not durable.
+func (b *Buffer[T]) SetWAL(log *wal.Log[T]) {
+	if b == nil {
+		return
+	}
+
+	b.journal = log
+}
+
+// log appends the message of an envelope to the write-ahead log and records
+// its sequence number in the envelope. Does nothing if the Buffer is not
+// durable.
+//
+// Parameters:
+//   - env: The envelope of the message.
+//
+// Returns:
+//   - error: An error if the message could not be appended.
+func (b *Buffer[T]) log(env *envelope[T]) error {
+	if b.journal == nil {
+		return nil
+	}
+
+	seq, err := b.journal.Append(env.value)
+	if err != nil {
+		return err
+	}
+
+	env.seq = seq
+
+	return nil
+}
+
+// commit acknowledges the message of an envelope in the write-ahead log so
+// that it is never replayed. Does nothing if the message is not logged.
+//
+// Parameters:
+//   - env: The envelope of the message.
+func (b *Buffer[T]) commit(env envelope[T]) {
+	if b.journal == nil || env.seq == 0 {
+		return
+	}
+
+	_ = b.journal.Ack(env.seq)
+}
@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// waitBounds are the upper bounds of the buckets of the wait-time histogram.
+// The last bucket, which has no upper bound, is implicit.
+var waitBounds = [...]time.Duration{
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+	time.Minute,
+}
+
+// Histogram is a snapshot of the distribution of the time messages wait in a
+// Buffer before being handed out.
+type Histogram struct {
+	// Bounds are the upper bounds of the buckets. The last bucket, which has
+	// no upper bound, is implicit.
+	Bounds []time.Duration
+
+	// Counts are the number of observations of each bucket. It has one more
+	// element than Bounds.
+	Counts []uint64
+
+	// Count is the total number of observations.
+	Count uint64
+
+	// Sum is the sum of the observations.
+	Sum time.Duration
+}
+
+// Stats is a snapshot of the statistics of a Buffer.
+type Stats struct {
+	// Enqueued is the number of messages that entered the queue, including
+	// delayed messages once they are due and redelivered messages.
+	Enqueued uint64
+
+	// Dequeued is the number of messages handed out to a receiver.
+	Dequeued uint64
+
+	// Depth is the number of messages in the queue.
+	Depth int
+
+	// HighWater is the highest depth ever reached.
+	HighWater int
+
+	// Wait is the distribution of the time messages waited in the queue
+	// before being handed out.
+	Wait Histogram
+
+	// BlockedSend is the total time senders were blocked waiting for the
+	// Buffer to accept their message.
+	BlockedSend time.Duration
+
+	// Counts are the delivery counts.
+	Counts Counts
+}
+
+// metrics are the live statistics of a Buffer. The zero value is ready to use.
+type metrics struct {
+	// enqueued and dequeued are the number of messages that entered and left
+	// the queue.
+	enqueued, dequeued atomic.Uint64
+
+	// depth is the number of messages in the queue. It is updated by the size
+	// observer of the queue.
+	depth atomic.Int64
+
+	// highWater is the highest depth ever reached.
+	highWater atomic.Int64
+
+	// buckets are the counts of the wait-time histogram, one per bound plus
+	// the implicit last bucket.
+	buckets [len(waitBounds) + 1]atomic.Uint64
+
+	// waitSum is the sum of the wait times, in nanoseconds.
+	waitSum atomic.Int64
+
+	// blocked is the time senders were blocked, in nanoseconds.
+	blocked atomic.Int64
+}
+
+// onEnqueue records that a message entered the queue.
+func (m *metrics) onEnqueue() {
+	m.enqueued.Add(1)
+}
+
+// onSize records the size of the queue, as reported by its size observation.
+//
+// Parameters:
+//   - size: The size of the queue.
+func (m *metrics) onSize(size int) {
+	depth := int64(size)
+
+	m.depth.Store(depth)
+
+	for {
+		high := m.highWater.Load()
+		if depth <= high || m.highWater.CompareAndSwap(high, depth) {
+			break
+		}
+	}
+}
+
+// onDequeue records that a message was handed out.
+//
+// Parameters:
+//   - wait: The time the message waited in the queue.
+func (m *metrics) onDequeue(wait time.Duration) {
+	m.dequeued.Add(1)
+
+	i := 0
+
+	for i < len(waitBounds) && wait > waitBounds[i] {
+		i++
+	}
+
+	m.buckets[i].Add(1)
+	m.waitSum.Add(int64(wait))
+}
+
+// snapshot returns a snapshot of the statistics.
+//
+// Returns:
+//   - Stats: The snapshot.
+func (m *metrics) snapshot() Stats {
+	wait := Histogram{
+		Bounds: make([]time.Duration, len(waitBounds)),
+		Counts: make([]uint64, len(m.buckets)),
+		Sum:    time.Duration(m.waitSum.Load()),
+	}
+
+	copy(wait.Bounds, waitBounds[:])
+
+	for i := range m.buckets {
+		wait.Counts[i] = m.buckets[i].Load()
+		wait.Count += wait.Counts[i]
+	}
+
+	return Stats{
+		Enqueued:    m.enqueued.Load(),
+		Dequeued:    m.dequeued.Load(),
+		Depth:       int(m.depth.Load()),
+		HighWater:   int(m.highWater.Load()),
+		Wait:        wait,
+		BlockedSend: time.Duration(m.blocked.Load()),
+	}
+}
+
+// enqueue puts an envelope in the queue and stamps it with the time it
+// entered it.
+//
+// Parameters:
+//   - env: The envelope to enqueue.
+func (b *Buffer[T]) enqueue(env envelope[T]) {
+	env.enqueuedAt = b.clock.Now()
+
+	b.qmu.Lock()
+	defer b.qmu.Unlock()
+
+	err := b.q.Enqueue(env)
+	if err == nil {
+		b.stats.onEnqueue()
+	}
+}
+
+// Stats returns a snapshot of the statistics of the Buffer.
+//
+// Returns:
+//   - Stats: The snapshot. The zero value if the receiver is nil.
+func (b *Buffer[T]) Stats() Stats {
+	if b == nil {
+		return Stats{}
+	}
+
+	stats := b.stats.snapshot()
+	stats.Counts = b.Counts()
+
+	return stats
+}
@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"container/heap"
+	"sync"
+
+	"github.com/PlayerR9/go-safe/common"
+	lls "github.com/PlayerR9/go-safe/queue"
+	sbj "github.com/PlayerR9/go-safe/subject"
+)
+
+// priorityItem is an item of a priorityQueue.
+type priorityItem[T any] struct {
+	// value is the message.
+	value T
+
+	// seq is the insertion order of the message. It keeps messages with the
+	// same priority in FIFO order.
+	seq uint64
+}
+
+// priorityHeap is a max-heap of priorityItems. It implements heap.Interface.
+type priorityHeap[T any] struct {
+	// items are the items of the heap.
+	items []priorityItem[T]
+
+	// cmp is the comparator of the messages.
+	cmp func(a, b T) int
+}
+
+// Len implements the heap.Interface interface.
+func (h *priorityHeap[T]) Len() int {
+	return len(h.items)
+}
+
+// Less implements the heap.Interface interface.
+func (h *priorityHeap[T]) Less(i, j int) bool {
+	res := h.cmp(h.items[i].value, h.items[j].value)
+	if res != 0 {
+		return res > 0
+	}
+
+	return h.items[i].seq < h.items[j].seq
+}
+
+// Swap implements the heap.Interface interface.
+func (h *priorityHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+// Push implements the heap.Interface interface.
+func (h *priorityHeap[T]) Push(x any) {
+	h.items = append(h.items, x.(priorityItem[T]))
+}
+
+// Pop implements the heap.Interface interface.
+func (h *priorityHeap[T]) Pop() any {
+	n := len(h.items)
+
+	item := h.items[n-1]
+	h.items[n-1] = priorityItem[T]{}
+	h.items = h.items[:n-1]
+
+	return item
+}
+
+// priorityQueue is a thread-safe store that hands out the message with the
+// highest priority first. Messages with the same priority are handed out in
+// FIFO order.
+type priorityQueue[T any] struct {
+	// h is the underlying heap.
+	h priorityHeap[T]
+
+	// seq is the sequence number of the next message.
+	seq uint64
+
+	// mu is the mutex that synchronizes the heap.
+	mu sync.RWMutex
+
+	// observers are the observers of the size. They are called while the
+	// queue is locked.
+	observers sbj.Notifier[int]
+}
+
+// newPriorityQueue creates a new priorityQueue.
+//
+// Parameters:
+//   - cmp: The comparator of the messages. A positive result means that a
+//     has a higher priority than b.
+//
+// Returns:
+//   - *priorityQueue[T]: The new priorityQueue. Never returns nil.
+func newPriorityQueue[T any](cmp func(a, b T) int) *priorityQueue[T] {
+	return &priorityQueue[T]{
+		h: priorityHeap[T]{
+			cmp: cmp,
+		},
+	}
+}
+
+// Enqueue implements the store interface.
+func (pq *priorityQueue[T]) Enqueue(value T) error {
+	if pq == nil {
+		return common.ErrNilReceiver
+	}
+
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	heap.Push(&pq.h, priorityItem[T]{
+		value: value,
+		seq:   pq.seq,
+	})
+
+	pq.seq++
+
+	pq.observers.Publish(pq.h.Len())
+
+	return nil
+}
+
+// Peek implements the store interface.
+func (pq *priorityQueue[T]) Peek() (T, error) {
+	if pq == nil {
+		return *new(T), common.ErrNilReceiver
+	}
+
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+
+	if pq.h.Len() == 0 {
+		return *new(T), lls.ErrEmptyQueue
+	}
+
+	return pq.h.items[0].value, nil
+}
+
+// Dequeue implements the store interface.
+func (pq *priorityQueue[T]) Dequeue() (T, error) {
+	if pq == nil {
+		return *new(T), common.ErrNilReceiver
+	}
+
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	if pq.h.Len() == 0 {
+		return *new(T), lls.ErrEmptyQueue
+	}
+
+	item := heap.Pop(&pq.h).(priorityItem[T])
+
+	pq.observers.Publish(pq.h.Len())
+
+	return item.value, nil
+}
+
+// Reset implements the store interface.
+func (pq *priorityQueue[T]) Reset() {
+	if pq == nil {
+		return
+	}
+
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	if pq.h.Len() == 0 {
+		return
+	}
+
+	clear(pq.h.items)
+	pq.h.items = nil
+
+	pq.observers.Publish(0)
+}
+
+// Slice implements the store interface.
+//
+// The messages are not in priority order.
+func (pq *priorityQueue[T]) Slice() []T {
+	if pq == nil {
+		return nil
+	}
+
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+
+	if pq.h.Len() == 0 {
+		return nil
+	}
+
+	slice := make([]T, 0, pq.h.Len())
+
+	for _, item := range pq.h.items {
+		slice = append(slice, item.value)
+	}
+
+	return slice
+}
+
+// ObserveSizeSync implements the store interface.
+func (pq *priorityQueue[T]) ObserveSizeSync(fn sbj.Action[int]) error {
+	if fn == nil {
+		return nil
+	} else if pq == nil {
+		return common.ErrNilReceiver
+	}
+
+	_ = pq.observers.Observe(fn)
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"cmp"
+	"testing"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	type message struct {
+		priority int
+		id       int
+	}
+
+	pq := newPriorityQueue(func(a, b message) int {
+		return cmp.Compare(a.priority, b.priority)
+	})
+
+	messages := []message{
+		{priority: 0, id: 0},
+		{priority: 0, id: 1},
+		{priority: 5, id: 2},
+		{priority: 1, id: 3},
+		{priority: 5, id: 4},
+		{priority: 0, id: 5},
+	}
+
+	for _, msg := range messages {
+		err := pq.Enqueue(msg)
+		if err != nil {
+			t.Fatalf("could not enqueue %v: %v", msg, err)
+		}
+	}
+
+	expected := []int{2, 4, 3, 0, 1, 5}
+
+	for _, id := range expected {
+		msg, err := pq.Dequeue()
+		if err != nil {
+			t.Fatalf("could not dequeue: %v", err)
+		} else if msg.id != id {
+			t.Fatalf("expected message %d, got %d", id, msg.id)
+		}
+	}
+
+	_, err := pq.Dequeue()
+	if err == nil {
+		t.Fatalf("expected the queue to be empty")
+	}
+}
@@ -0,0 +1,254 @@
+package internal
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// scheduled is a message that is delivered at a given time.
+type scheduled[T any] struct {
+	// env is the envelope of the message.
+	env envelope[T]
+
+	// due is the time at which the message becomes visible.
+	due time.Time
+
+	// lease is the identifier of the in-flight message whose visibility timeout
+	// is due. Zero if the entry is a delayed message.
+	lease uint64
+}
+
+// newSchedule creates the heap of the scheduled messages. The message that is
+// due first is at the top.
+//
+// Returns:
+//   - priorityHeap[scheduled[T]]: The heap.
+func newSchedule[T any]() priorityHeap[scheduled[T]] {
+	return priorityHeap[scheduled[T]]{
+		cmp: func(a, b scheduled[T]) int {
+			return b.due.Compare(a.due)
+		},
+	}
+}
+
+// SetClock sets the clock used to schedule delayed messages. It must be called
+// before Start.
+//
+// Parameters:
+//   - clock: The clock to use. If nil, common.RealClock is used.
+func (b *Buffer[T]) SetClock(clock common.Clock) {
+	if b == nil {
+		return
+	}
+
+	b.clock = clock
+}
+
+// SendAt sends a message that stays invisible to Receive until the given time.
+// Its time-to-live only starts once it becomes visible. If the Buffer is closed
+// before that time, the message is dead-lettered instead.
+//
+// Parameters:
+//   - msg: The message to send.
+//   - at: The time at which the message becomes visible. If it is not in the
+//     future, the message is visible right away.
+//
+// Returns:
+//   - error: An error if the message could not be sent.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrAlreadyClosed: If the Buffer is closed.
+func (b *Buffer[T]) SendAt(msg T, at time.Time) error {
+	if b == nil {
+		return common.ErrNilReceiver
+	} else if b.clock == nil {
+		return ErrAlreadyClosed
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.sendTo == nil {
+		return ErrAlreadyClosed
+	}
+
+	env := b.wrap(msg, b.ttl, at)
+
+	err := b.log(&env)
+	if err != nil {
+		return err
+	}
+
+	b.smu.Lock()
+
+	b.push(scheduled[T]{
+		env: env,
+		due: at,
+	})
+
+	b.smu.Unlock()
+
+	return nil
+}
+
+// push adds an entry to the schedule and wakes the scheduler up. The caller
+// must hold smu.
+//
+// Parameters:
+//   - entry: The entry to add.
+func (b *Buffer[T]) push(entry scheduled[T]) {
+	heap.Push(&b.schedule, priorityItem[scheduled[T]]{
+		value: entry,
+		seq:   b.seq,
+	})
+
+	b.seq++
+
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// SendAfter sends a message that stays invisible to Receive until the given
+// delay has elapsed. Its time-to-live only starts once it becomes visible. If
+// the Buffer is closed before the delay has elapsed, the message is
+// dead-lettered instead.
+//
+// Parameters:
+//   - msg: The message to send.
+//   - delay: The delay after which the message becomes visible. If it is not
+//     positive, the message is visible right away.
+//
+// Returns:
+//   - error: An error if the message could not be sent.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrAlreadyClosed: If the Buffer is closed.
+func (b *Buffer[T]) SendAfter(msg T, delay time.Duration) error {
+	if b == nil {
+		return common.ErrNilReceiver
+	} else if b.clock == nil {
+		return ErrAlreadyClosed
+	}
+
+	return b.SendAt(msg, b.clock.Now().Add(delay))
+}
+
+// releaseDue moves the scheduled messages that are due into the store. If
+// closing is true, the delayed messages that are not due are dead-lettered so
+// that they are never handed out early, and the schedule is emptied.
+//
+// The in-flight messages whose visibility timeout is due are redelivered, or
+// dead-lettered if they failed the maximum number of attempts. If closing is
+// true, the in-flight messages whose visibility timeout is not due are left in
+// flight.
+//
+// Parameters:
+//   - closing: Whether the Buffer is closing.
+//
+// Returns:
+//   - time.Time: The due time of the next scheduled message.
+//   - bool: True if there is a next scheduled message, false otherwise.
+func (b *Buffer[T]) releaseDue(closing bool) (time.Time, bool) {
+	b.smu.Lock()
+	defer b.smu.Unlock()
+
+	now := b.clock.Now()
+
+	for b.schedule.Len() > 0 {
+		next := b.schedule.items[0].value
+		if !closing && next.due.After(now) {
+			return next.due, true
+		}
+
+		_ = heap.Pop(&b.schedule)
+
+		env := next.env
+
+		if next.lease == 0 && next.due.After(now) {
+			b.deadLetter(env)
+			continue
+		} else if next.lease != 0 {
+			if next.due.After(now) {
+				continue
+			}
+
+			var ok bool
+
+			env, ok = b.inFlight[next.lease]
+			if !ok {
+				// The message was already settled.
+				continue
+			}
+
+			delete(b.inFlight, next.lease)
+
+			env.attempts++
+
+			if b.maxAttempts > 0 && env.attempts >= b.maxAttempts {
+				b.deadLetter(env)
+				continue
+			}
+		}
+
+		b.enqueue(env)
+	}
+
+	return time.Time{}, false
+}
+
+// runScheduler is a method of the Buffer type that makes the scheduled messages
+// visible once they are due. It wakes up exactly when the next scheduled
+// message becomes due. Once stop is closed, the messages that are due are made
+// visible and the remaining delayed messages are dead-lettered.
+//
+// It must be run in a separate goroutine to avoid blocking the main thread.
+//
+// Parameters:
+//   - stop: The channel that stops the scheduler once closed.
+//   - done: The channel that is closed once the scheduler has stopped.
+func (b *Buffer[T]) runScheduler(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	for {
+		next, ok := b.releaseDue(false)
+
+		var timer common.Timer
+		var fire <-chan time.Time
+
+		if ok {
+			timer = b.clock.NewTimer(next.Sub(b.clock.Now()))
+
+			// The clock may have moved between releaseDue and the creation of
+			// the timer.
+			if !next.After(b.clock.Now()) {
+				timer.Stop()
+				continue
+			}
+
+			fire = timer.C()
+		}
+
+		select {
+		case <-fire:
+		case <-b.wake:
+		case <-stop:
+			if timer != nil {
+				timer.Stop()
+			}
+
+			_, _ = b.releaseDue(true)
+
+			return
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package internal
+
+import "reflect"
+
+// SelectCase returns the case of reflect.Select that receives a message from
+// the Buffer. The message must then be accepted with Accept.
+//
+// Returns:
+//   - reflect.SelectCase: The case. It is ignored by reflect.Select if the
+//     receiver is nil or the Buffer was never started.
+func (b *Buffer[T]) SelectCase() reflect.SelectCase {
+	sc := reflect.SelectCase{
+		Dir: reflect.SelectRecv,
+	}
+
+	if b != nil && b.receiveFrom != nil {
+		sc.Chan = reflect.ValueOf(b.receiveFrom)
+	}
+
+	return sc
+}
+
+// Accept completes the receive of a message selected through SelectCase.
+//
+// Parameters:
+//   - v: The value received by reflect.Select.
+//   - ok: Whether the value was received, as reported by reflect.Select.
+//
+// Returns:
+//   - T: The message.
+//   - error: The close reason if the Buffer is closed.
+func (b *Buffer[T]) Accept(v reflect.Value, ok bool) (T, error) {
+	if !ok {
+		// The close reason is set before receiveFrom is closed.
+		return *new(T), b.err
+	}
+
+	env := v.Interface().(envelope[T])
+
+	b.deliver(env)
+
+	return env.value, nil
+}
+
+// TryReceive receives the next pending message only if one is ready, without
+// waiting for it. Expired messages are dead-lettered instead.
+//
+// Select relies on it so that its default case does not win while messages are
+// pending: they are only handed off through the channel of SelectCase to a
+// receiver that is already waiting.
+//
+// Returns:
+//   - T: The message.
+//   - bool: True if a message was received, false otherwise.
+func (b *Buffer[T]) TryReceive() (T, bool) {
+	if b == nil {
+		return *new(T), false
+	}
+
+	b.qmu.Lock()
+	defer b.qmu.Unlock()
+
+	for b.q != nil && !b.aborted.Load() {
+		env, err := b.q.Dequeue()
+		if err != nil {
+			break
+		}
+
+		now := b.clock.Now()
+
+		if env.isExpired(now) {
+			b.expired.Add(1)
+			b.deadLetter(env)
+
+			continue
+		}
+
+		b.stats.onDequeue(max(now.Sub(env.enqueuedAt), 0))
+		b.deliver(env)
+
+		return env.value, true
+	}
+
+	return *new(T), false
+}
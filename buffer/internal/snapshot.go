@@ -0,0 +1,332 @@
+package internal
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// snapshotVersion is the version of the snapshot format.
+const snapshotVersion byte = 1
+
+// appendTime appends a time to a snapshot. The zero time is encoded as 0.
+//
+// Parameters:
+//   - data: The snapshot.
+//   - t: The time to append.
+//
+// Returns:
+//   - []byte: The snapshot.
+func appendTime(data []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return binary.AppendVarint(data, 0)
+	}
+
+	return binary.AppendVarint(data, t.UnixNano())
+}
+
+// readTime reads a time appended with appendTime.
+//
+// Parameters:
+//   - r: The reader of the snapshot.
+//
+// Returns:
+//   - time.Time: The time.
+//   - error: An error if the time could not be read.
+func readTime(r io.ByteReader) (time.Time, error) {
+	nsec, err := binary.ReadVarint(r)
+	if err != nil || nsec == 0 {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, nsec), nil
+}
+
+// encodeEntries encodes the pending messages of a Buffer.
+//
+// Parameters:
+//   - entries: The pending messages. The due time of the visible ones is zero.
+//   - codec: The codec of the messages.
+//
+// Returns:
+//   - []byte: The snapshot.
+//   - error: An error if a message could not be encoded.
+func encodeEntries[T any](entries []scheduled[T], codec common.Codec[T]) ([]byte, error) {
+	data := []byte{snapshotVersion}
+	data = binary.AppendUvarint(data, uint64(len(entries)))
+
+	for i, entry := range entries {
+		payload, err := codec.Encode(entry.env.value)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode message %d: %w", i, err)
+		}
+
+		data = appendTime(data, entry.due)
+		data = appendTime(data, entry.env.expiresAt)
+		data = binary.AppendUvarint(data, uint64(entry.env.attempts))
+		data = common.AppendFrame(data, payload)
+	}
+
+	return data, nil
+}
+
+// decodeEntries decodes a snapshot encoded with encodeEntries.
+//
+// Parameters:
+//   - data: The snapshot.
+//   - codec: The codec of the messages.
+//
+// Returns:
+//   - []scheduled[T]: The pending messages.
+//   - error: An error if the snapshot could not be decoded.
+func decodeEntries[T any](data []byte, codec common.Codec[T]) ([]scheduled[T], error) {
+	r := bytes.NewReader(data)
+
+	version, err := r.ReadByte()
+	if err != nil || version != snapshotVersion {
+		return nil, ErrBadSnapshot
+	}
+
+	n, err := binary.ReadUvarint(r)
+	if err != nil || n > uint64(r.Len()) {
+		return nil, ErrBadSnapshot
+	}
+
+	entries := make([]scheduled[T], 0, n)
+
+	for i := uint64(0); i < n; i++ {
+		var entry scheduled[T]
+
+		entry.due, err = readTime(r)
+		if err != nil {
+			return nil, ErrBadSnapshot
+		}
+
+		entry.env.expiresAt, err = readTime(r)
+		if err != nil {
+			return nil, ErrBadSnapshot
+		}
+
+		attempts, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, ErrBadSnapshot
+		}
+
+		entry.env.attempts = int(attempts)
+
+		payload, ok := common.ReadFrame(r)
+		if !ok {
+			return nil, ErrBadSnapshot
+		}
+
+		entry.env.value, err = codec.Decode(payload)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode message %d: %w", i, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if r.Len() != 0 {
+		return nil, ErrBadSnapshot
+	}
+
+	return entries, nil
+}
+
+// pending returns the pending messages of the Buffer, visible ones first. The
+// caller must hold smu and qmu.
+//
+// Returns:
+//   - []scheduled[T]: The pending messages. The due time of the visible ones
+//     is zero.
+//   - []priorityItem[scheduled[T]]: The visibility timeouts of the in-flight
+//     messages, which are not pending.
+func (b *Buffer[T]) pending() ([]scheduled[T], []priorityItem[scheduled[T]]) {
+	var entries []scheduled[T]
+
+	for _, env := range b.q.Slice() {
+		entries = append(entries, scheduled[T]{
+			env: env,
+		})
+	}
+
+	var leases []priorityItem[scheduled[T]]
+
+	for _, item := range b.schedule.items {
+		if item.value.lease != 0 {
+			leases = append(leases, item)
+		} else {
+			entries = append(entries, item.value)
+		}
+	}
+
+	return entries, leases
+}
+
+// Snapshot encodes the pending messages of the Buffer in a snapshot, along
+// with their expiration time, failed attempts and, for delayed messages, due
+// time. The Buffer is left unchanged, so restoring the snapshot in another
+// Buffer while this one is still running duplicates the messages; use Drain to
+// move them instead.
+//
+// Messages are only handed off to a receiver that is waiting for them, so none
+// is held in the hand-off channel. In-flight messages are not part of the
+// snapshot.
+//
+// Parameters:
+//   - codec: The codec of the messages.
+//
+// Returns:
+//   - []byte: The snapshot.
+//   - error: An error if the snapshot could not be taken.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the codec is nil.
+//   - ErrAlreadyClosed: If the Buffer is closed.
+//   - any other error: If a message could not be encoded.
+func (b *Buffer[T]) Snapshot(codec common.Codec[T]) ([]byte, error) {
+	if b == nil {
+		return nil, common.ErrNilReceiver
+	} else if codec == nil {
+		return nil, common.NewErrNilParam("codec")
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.sendTo == nil {
+		return nil, ErrAlreadyClosed
+	}
+
+	b.smu.Lock()
+	defer b.smu.Unlock()
+
+	b.qmu.Lock()
+	defer b.qmu.Unlock()
+
+	entries, _ := b.pending()
+
+	return encodeEntries(entries, codec)
+}
+
+// Drain is the same as Snapshot, except that it takes the pending messages out
+// of the Buffer. The Buffer keeps running but is left without pending
+// messages, so that restoring the snapshot in another Buffer moves the
+// messages without duplicating them. Nothing is taken out if a message cannot
+// be encoded.
+//
+// The messages sent while the snapshot is taken may or may not be part of it;
+// stop the senders first to move every message.
+//
+// Parameters:
+//   - codec: The codec of the messages.
+//
+// Returns:
+//   - []byte: The snapshot.
+//   - error: An error if the snapshot could not be taken.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the codec is nil.
+//   - ErrAlreadyClosed: If the Buffer is closed.
+//   - any other error: If a message could not be encoded.
+func (b *Buffer[T]) Drain(codec common.Codec[T]) ([]byte, error) {
+	if b == nil {
+		return nil, common.ErrNilReceiver
+	} else if codec == nil {
+		return nil, common.NewErrNilParam("codec")
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.sendTo == nil {
+		return nil, ErrAlreadyClosed
+	}
+
+	b.smu.Lock()
+	defer b.smu.Unlock()
+
+	b.qmu.Lock()
+	defer b.qmu.Unlock()
+
+	entries, leases := b.pending()
+
+	data, err := encodeEntries(entries, codec)
+	if err != nil {
+		return nil, err
+	}
+
+	b.q.Reset()
+
+	for _, entry := range entries {
+		b.commit(entry.env)
+	}
+
+	b.schedule.items = leases
+	heap.Init(&b.schedule)
+
+	return data, nil
+}
+
+// Restore decodes a snapshot taken with Snapshot or Drain and adds its
+// messages to the Buffer. Delayed messages stay invisible until their due
+// time. Nothing is added if the snapshot cannot be decoded.
+//
+// Parameters:
+//   - data: The snapshot.
+//   - codec: The codec of the messages.
+//
+// Returns:
+//   - error: An error if the snapshot could not be restored.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the codec is nil.
+//   - ErrAlreadyClosed: If the Buffer is closed.
+//   - ErrBadSnapshot: If the snapshot is malformed.
+//   - any other error: If a message could not be decoded or logged.
+func (b *Buffer[T]) Restore(data []byte, codec common.Codec[T]) error {
+	if b == nil {
+		return common.ErrNilReceiver
+	} else if codec == nil {
+		return common.NewErrNilParam("codec")
+	}
+
+	entries, err := decodeEntries(data, codec)
+	if err != nil {
+		return err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.sendTo == nil {
+		return ErrAlreadyClosed
+	}
+
+	for _, entry := range entries {
+		err := b.log(&entry.env)
+		if err != nil {
+			return err
+		}
+
+		if entry.due.IsZero() {
+			b.enqueue(entry.env)
+			continue
+		}
+
+		b.smu.Lock()
+		b.push(entry)
+		b.smu.Unlock()
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	sbj "github.com/PlayerR9/go-safe/subject"
+)
+
+// store is the storage of the pending messages of a Buffer.
+type store[T any] interface {
+	// Enqueue adds a message to the store.
+	//
+	// Parameters:
+	//   - value: The message to add.
+	//
+	// Returns:
+	//   - error: An error if the receiver is nil.
+	Enqueue(value T) error
+
+	// Peek returns the next message to be handed out without removing it.
+	//
+	// Returns:
+	//   - T: The next message.
+	//   - error: An error if the store is empty or the receiver is nil.
+	Peek() (T, error)
+
+	// Dequeue removes and returns the next message to be handed out.
+	//
+	// Returns:
+	//   - T: The next message.
+	//   - error: An error if the store is empty or the receiver is nil.
+	Dequeue() (T, error)
+
+	// Reset removes all the messages from the store.
+	Reset()
+
+	// Slice returns a copy of the messages in the store.
+	//
+	// Returns:
+	//   - []T: A copy of the messages in the store.
+	Slice() []T
+
+	// ObserveSizeSync adds a synchronous observer to the size of the store.
+	//
+	// Parameters:
+	//   - fn: The function to be called when the size changes.
+	//
+	// Returns:
+	//   - error: An error if the receiver is nil.
+	ObserveSizeSync(fn sbj.Action[int]) error
+}
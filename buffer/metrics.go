@@ -0,0 +1,254 @@
+package buffer
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	internal "github.com/PlayerR9/go-safe/buffer/internal"
+)
+
+// Histogram is the distribution of the time messages wait in a buffer before
+// being handed out.
+type Histogram = internal.Histogram
+
+// Stats is a snapshot of the statistics of a buffer.
+type Stats = internal.Stats
+
+// observable is a buffer whose statistics can be exported, whatever the type of
+// its messages.
+type observable interface {
+	Stats() internal.Stats
+}
+
+// StatsOf returns a snapshot of the statistics of the buffer carried by the
+// context.
+//
+// Parameters:
+//   - ctx: The context created by NewContext.
+//
+// Returns:
+//   - Stats: The snapshot.
+//   - error: An error if the context does not carry a buffer of type T.
+func StatsOf[T any](ctx context.Context) (Stats, error) {
+	c, err := fromContext[T](ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	return c.buffer.Stats(), nil
+}
+
+var (
+	// registry are the named buffers by name.
+	registry map[string]observable
+
+	// registryMu is the mutex that synchronizes the registry.
+	registryMu sync.Mutex
+)
+
+// register adds a named buffer to the registry. It replaces any buffer
+// registered under the same name.
+//
+// Parameters:
+//   - name: The name of the buffer.
+//   - b: The buffer.
+func register(name string, b observable) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if registry == nil {
+		registry = make(map[string]observable)
+	}
+
+	registry[name] = b
+}
+
+// unregister removes a named buffer from the registry. Does nothing if another
+// buffer was registered under the same name since.
+//
+// Parameters:
+//   - name: The name of the buffer.
+//   - b: The buffer.
+func unregister(name string, b observable) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if registry[name] == b {
+		delete(registry, name)
+	}
+}
+
+// AllStats returns a snapshot of the statistics of every open buffer created
+// with the WithName option.
+//
+// Returns:
+//   - map[string]Stats: The snapshots by buffer name. Never returns nil.
+func AllStats() map[string]Stats {
+	registryMu.Lock()
+	buffers := maps.Clone(registry)
+	registryMu.Unlock()
+
+	all := make(map[string]Stats, len(buffers))
+
+	for name, b := range buffers {
+		all[name] = b.Stats()
+	}
+
+	return all
+}
+
+// PublishExpvar publishes the statistics of every named buffer as an expvar
+// variable whose value is the result of AllStats.
+//
+// Like expvar.Publish, it panics if a variable with the same name is already
+// published.
+//
+// Parameters:
+//   - name: The name of the expvar variable.
+func PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return AllStats()
+	}))
+}
+
+// labelEscaper escapes the value of a Prometheus label.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// promWriter writes metrics in the Prometheus text exposition format.
+type promWriter struct {
+	// w is the destination.
+	w io.Writer
+
+	// err is the first write error.
+	err error
+}
+
+// printf writes a formatted line. Does nothing once a write failed.
+//
+// Parameters:
+//   - format: The format of the line, without the trailing newline.
+//   - args: The arguments of the format.
+func (pw *promWriter) printf(format string, args ...any) {
+	if pw.err != nil {
+		return
+	}
+
+	_, pw.err = fmt.Fprintf(pw.w, format+"\n", args...)
+}
+
+// family writes a metric family of one sample per buffer.
+//
+// Parameters:
+//   - name: The name of the metric.
+//   - kind: The type of the metric.
+//   - help: The description of the metric.
+//   - names: The names of the buffers, sorted.
+//   - all: The statistics by buffer name.
+//   - value: The function that returns the value of the metric.
+func (pw *promWriter) family(name, kind, help string, names []string, all map[string]Stats, value func(s Stats) string) {
+	pw.printf("# HELP %s %s", name, help)
+	pw.printf("# TYPE %s %s", name, kind)
+
+	for _, n := range names {
+		pw.printf("%s{buffer=\"%s\"} %s", name, labelEscaper.Replace(n), value(all[n]))
+	}
+}
+
+// seconds formats a duration in seconds.
+//
+// Parameters:
+//   - d: The duration.
+//
+// Returns:
+//   - string: The number of seconds.
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
+
+// WritePrometheus writes the statistics of every named buffer in the Prometheus
+// text exposition format. Every sample has a "buffer" label holding the name
+// of the buffer.
+//
+// Parameters:
+//   - w: The destination.
+//
+// Returns:
+//   - error: An error if the statistics could not be written.
+func WritePrometheus(w io.Writer) error {
+	if w == nil {
+		return nil
+	}
+
+	all := AllStats()
+	names := slices.Sorted(maps.Keys(all))
+
+	pw := &promWriter{
+		w: w,
+	}
+
+	count := func(n uint64) string {
+		return strconv.FormatUint(n, 10)
+	}
+
+	pw.family("buffer_enqueued_total", "counter", "Messages that entered the queue.", names, all, func(s Stats) string {
+		return count(s.Enqueued)
+	})
+
+	pw.family("buffer_dequeued_total", "counter", "Messages handed out to a receiver.", names, all, func(s Stats) string {
+		return count(s.Dequeued)
+	})
+
+	pw.family("buffer_depth", "gauge", "Messages in the queue.", names, all, func(s Stats) string {
+		return strconv.Itoa(s.Depth)
+	})
+
+	pw.family("buffer_high_water", "gauge", "Highest depth ever reached.", names, all, func(s Stats) string {
+		return strconv.Itoa(s.HighWater)
+	})
+
+	pw.family("buffer_blocked_send_seconds_total", "counter", "Time senders were blocked.", names, all, func(s Stats) string {
+		return seconds(s.BlockedSend)
+	})
+
+	pw.family("buffer_expired_total", "counter", "Messages that expired before being handed out.", names, all, func(s Stats) string {
+		return count(s.Counts.Expired)
+	})
+
+	pw.family("buffer_dead_lettered_total", "counter", "Messages that were dead-lettered.", names, all, func(s Stats) string {
+		return count(s.Counts.DeadLettered)
+	})
+
+	pw.printf("# HELP buffer_wait_seconds Time messages waited in the queue.")
+	pw.printf("# TYPE buffer_wait_seconds histogram")
+
+	for _, n := range names {
+		s := all[n]
+		label := labelEscaper.Replace(n)
+
+		var cumulative uint64
+
+		for i, count := range s.Wait.Counts {
+			cumulative += count
+
+			le := "+Inf"
+			if i < len(s.Wait.Bounds) {
+				le = seconds(s.Wait.Bounds[i])
+			}
+
+			pw.printf("buffer_wait_seconds_bucket{buffer=\"%s\",le=\"%s\"} %d", label, le, cumulative)
+		}
+
+		pw.printf("buffer_wait_seconds_sum{buffer=\"%s\"} %s", label, seconds(s.Wait.Sum))
+		pw.printf("buffer_wait_seconds_count{buffer=\"%s\"} %d", label, s.Wait.Count)
+	}
+
+	return pw.err
+}
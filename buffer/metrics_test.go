@@ -0,0 +1,99 @@
+package buffer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+func TestStats(t *testing.T) {
+	const (
+		MaxCount int = 3
+	)
+
+	clock := common.NewManualClock(time.Unix(0, 0))
+
+	ctx, cancel := NewContext[int](context.Background(), WithClock[int](clock), WithName[int]("jobs"))
+
+	for i := 0; i < MaxCount; i++ {
+		err := Send(i).Run(ctx)
+		if err != nil {
+			t.Fatalf("could not send %d: %v", i, err)
+		}
+	}
+
+	waitFor := func(cond func(stats Stats) bool) Stats {
+		timeout := time.After(5 * time.Second)
+
+		for {
+			stats, err := StatsOf[int](ctx)
+			if err != nil {
+				t.Fatalf("could not get stats: %v", err)
+			} else if cond(stats) {
+				return stats
+			}
+
+			select {
+			case <-timeout:
+				t.Fatalf("timed out waiting for the stats, got %+v", stats)
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}
+
+	_ = waitFor(func(stats Stats) bool {
+		return stats.Depth == MaxCount
+	})
+
+	clock.Advance(2 * time.Second)
+
+	for i := 0; i < MaxCount; i++ {
+		var x int
+
+		err := Receive(&x).Run(ctx)
+		if err != nil {
+			t.Fatalf("could not receive %d: %v", i, err)
+		}
+	}
+
+	// The hand-off is recorded right after the receiver got the message.
+	stats := waitFor(func(stats Stats) bool {
+		return stats.Dequeued == uint64(MaxCount)
+	})
+
+	if stats.Enqueued != uint64(MaxCount) || stats.Dequeued != uint64(MaxCount) {
+		t.Fatalf("expected %d enqueued and dequeued, got %d and %d", MaxCount, stats.Enqueued, stats.Dequeued)
+	} else if stats.Depth != 0 || stats.HighWater != MaxCount {
+		t.Fatalf("expected depth %d and high-water %d, got %d and %d", 0, MaxCount, stats.Depth, stats.HighWater)
+	} else if stats.Wait.Count != uint64(MaxCount) || stats.Wait.Sum != time.Duration(MaxCount)*2*time.Second {
+		t.Fatalf("expected %d waits of 2s, got %d waits totaling %v", MaxCount, stats.Wait.Count, stats.Wait.Sum)
+	}
+
+	var sb strings.Builder
+
+	err := WritePrometheus(&sb)
+	if err != nil {
+		t.Fatalf("could not write metrics: %v", err)
+	}
+
+	for _, want := range []string{
+		`buffer_high_water{buffer="jobs"} 3`,
+		`buffer_wait_seconds_bucket{buffer="jobs",le="1"} 0`,
+		`buffer_wait_seconds_bucket{buffer="jobs",le="10"} 3`,
+		`buffer_wait_seconds_count{buffer="jobs"} 3`,
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Fatalf("expected %q in:\n%s", want, sb.String())
+		}
+	}
+
+	cancel()
+
+	_, ok := AllStats()["jobs"]
+	if ok {
+		t.Fatalf("expected closed buffer to be unregistered")
+	}
+}
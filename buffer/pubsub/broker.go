@@ -0,0 +1,403 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+
+	internal "github.com/PlayerR9/go-safe/buffer/internal"
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// Message is a message published to a topic.
+type Message[T any] struct {
+	// Topic is the topic the message was published to.
+	Topic string
+
+	// Payload is the content of the message.
+	Payload T
+}
+
+// Subscription is the queue of the messages published to the topics that match
+// a pattern. Every subscription has its own buffer so that a slow subscriber
+// never blocks the publishers nor the other subscribers.
+type Subscription[T any] struct {
+	// id is the identifier of the subscription.
+	id uint64
+
+	// pattern is the pattern of the subscription.
+	pattern string
+
+	// buffer is the buffer of the messages not received yet.
+	buffer *internal.Buffer[Message[T]]
+
+	// broker is the broker of the subscription.
+	broker *Broker[T]
+}
+
+// Pattern returns the pattern of the subscription.
+//
+// Returns:
+//   - string: The pattern. Empty if the receiver is nil.
+func (s *Subscription[T]) Pattern() string {
+	if s == nil {
+		return ""
+	}
+
+	return s.pattern
+}
+
+// Receive implements the common.Receiver interface.
+//
+// It blocks until a message is available. It returns false once the
+// subscription is cancelled, or once the broker is closed and the pending
+// messages were either received or discarded.
+func (s *Subscription[T]) Receive() (Message[T], bool) {
+	if s == nil {
+		return Message[T]{}, false
+	}
+
+	msg, err := s.buffer.Receive()
+	if err != nil {
+		return Message[T]{}, false
+	}
+
+	return msg, true
+}
+
+// Unsubscribe cancels the subscription. The messages that were not received
+// yet are discarded. Does nothing if the subscription is already cancelled.
+func (s *Subscription[T]) Unsubscribe() {
+	if s == nil {
+		return
+	}
+
+	s.broker.unsubscribe(s.id)
+}
+
+// Broker is a topic-based publish/subscribe broker. Topics are made of levels
+// separated by Separator, such as "orders.created", and subscriptions use
+// patterns that may contain the SingleLevel and MultiLevel wildcards, such as
+// "orders.*".
+//
+// An empty Broker is created by using the `b := new(Broker[T])` constructor.
+type Broker[T any] struct {
+	// subs are the active subscriptions by identifier.
+	subs map[uint64]*Subscription[T]
+
+	// retained are the retained messages by topic.
+	retained map[string]T
+
+	// lastID is the identifier of the last subscription.
+	lastID uint64
+
+	// closed is true once the broker is closed.
+	closed bool
+
+	// mu is the mutex that synchronizes the broker.
+	mu sync.RWMutex
+}
+
+// Subscribe subscribes to the topics that match a pattern. The retained
+// messages of the matching topics are delivered first.
+//
+// Parameters:
+//   - pattern: The pattern of the topics, such as "orders.*".
+//
+// Returns:
+//   - *Subscription[T]: The subscription. Nil if an error occurred.
+//   - error: An error if the subscription could not be created.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the pattern is not valid.
+//   - ErrBrokerClosed: If the broker is closed.
+func (b *Broker[T]) Subscribe(pattern string) (*Subscription[T], error) {
+	if b == nil {
+		return nil, common.ErrNilReceiver
+	} else if !validPattern(pattern) {
+		return nil, common.NewErrBadParam("pattern", "is not a valid pattern")
+	}
+
+	buffer := new(internal.Buffer[Message[T]])
+
+	err := buffer.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		buffer.Close()
+		return nil, ErrBrokerClosed
+	}
+
+	b.lastID++
+
+	s := &Subscription[T]{
+		id:      b.lastID,
+		pattern: pattern,
+		buffer:  buffer,
+		broker:  b,
+	}
+
+	for topic, payload := range b.retained {
+		if Match(pattern, topic) {
+			_ = buffer.Send(Message[T]{
+				Topic:   topic,
+				Payload: payload,
+			})
+		}
+	}
+
+	if b.subs == nil {
+		b.subs = make(map[uint64]*Subscription[T])
+	}
+
+	b.subs[s.id] = s
+
+	return s, nil
+}
+
+// unsubscribe cancels a subscription.
+//
+// Parameters:
+//   - id: The identifier of the subscription.
+func (b *Broker[T]) unsubscribe(id uint64) {
+	b.mu.Lock()
+
+	s, ok := b.subs[id]
+	if ok {
+		delete(b.subs, id)
+	}
+
+	b.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	// Aborting does not wait for the subscriber, which has usually stopped
+	// reading, to receive the messages published meanwhile.
+	s.buffer.Abort(nil)
+}
+
+// publish sends a message to the subscriptions whose pattern matches its
+// topic. The caller must hold the lock.
+//
+// Parameters:
+//   - msg: The message to send.
+func (b *Broker[T]) publish(msg Message[T]) {
+	for _, s := range b.subs {
+		if Match(s.pattern, msg.Topic) {
+			_ = s.buffer.Send(msg)
+		}
+	}
+}
+
+// Publish publishes a message to a topic. It never waits for the subscribers
+// to receive the message.
+//
+// Parameters:
+//   - topic: The topic, such as "orders.created".
+//   - payload: The content of the message.
+//
+// Returns:
+//   - error: An error if the message could not be published.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the topic is not valid.
+//   - ErrBrokerClosed: If the broker is closed.
+func (b *Broker[T]) Publish(topic string, payload T) error {
+	if b == nil {
+		return common.ErrNilReceiver
+	} else if !validTopic(topic) {
+		return common.NewErrBadParam("topic", "is not a valid topic")
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	b.publish(Message[T]{
+		Topic:   topic,
+		Payload: payload,
+	})
+
+	return nil
+}
+
+// PublishRetained publishes a message to a topic and retains it as the last
+// message of the topic. The retained message is delivered to every future
+// subscription whose pattern matches the topic.
+//
+// Parameters:
+//   - topic: The topic, such as "orders.created".
+//   - payload: The content of the message.
+//
+// Returns:
+//   - error: An error if the message could not be published.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the topic is not valid.
+//   - ErrBrokerClosed: If the broker is closed.
+func (b *Broker[T]) PublishRetained(topic string, payload T) error {
+	if b == nil {
+		return common.ErrNilReceiver
+	} else if !validTopic(topic) {
+		return common.NewErrBadParam("topic", "is not a valid topic")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	if b.retained == nil {
+		b.retained = make(map[string]T)
+	}
+
+	b.retained[topic] = payload
+
+	b.publish(Message[T]{
+		Topic:   topic,
+		Payload: payload,
+	})
+
+	return nil
+}
+
+// Retained returns the retained message of a topic.
+//
+// Parameters:
+//   - topic: The topic.
+//
+// Returns:
+//   - T: The retained message. The zero value if there is none.
+//   - bool: True if the topic has a retained message, false otherwise.
+func (b *Broker[T]) Retained(topic string) (T, bool) {
+	if b == nil {
+		return *new(T), false
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	payload, ok := b.retained[topic]
+	return payload, ok
+}
+
+// ClearRetained removes the retained message of a topic. Does nothing if the
+// topic has no retained message.
+//
+// Parameters:
+//   - topic: The topic.
+func (b *Broker[T]) ClearRetained(topic string) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.retained, topic)
+}
+
+// Close closes the broker right away. The messages the subscribers did not
+// receive yet are discarded, so a subscriber that stopped reading never blocks
+// it. Use CloseWithContext to let the subscribers receive them. Does nothing if
+// the broker is already closed.
+func (b *Broker[T]) Close() {
+	if b == nil {
+		return
+	}
+
+	for _, s := range b.detach() {
+		s.buffer.Abort(ErrBrokerClosed)
+	}
+}
+
+// CloseWithContext closes the broker and lets the subscribers receive their
+// pending messages until the context is done. The messages of the subscribers
+// that are still not received then are discarded. Does nothing if the broker
+// is already closed.
+//
+// Parameters:
+//   - ctx: The context that bounds the wait.
+//
+// Returns:
+//   - error: The error of the context if a subscriber did not receive all of
+//     its messages in time, nil otherwise.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If ctx is nil.
+//   - context.Canceled, context.DeadlineExceeded: If the context is done
+//     before every pending message is received.
+func (b *Broker[T]) CloseWithContext(ctx context.Context) error {
+	if b == nil {
+		return common.ErrNilReceiver
+	} else if ctx == nil {
+		return common.NewErrNilParam("ctx")
+	}
+
+	subs := b.detach()
+
+	errs := make([]error, len(subs))
+
+	var wg sync.WaitGroup
+
+	wg.Add(len(subs))
+
+	for i, s := range subs {
+		go func() {
+			defer wg.Done()
+
+			errs[i] = s.buffer.CloseWithContext(ctx)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// detach marks the broker as closed and removes its subscriptions.
+//
+// Returns:
+//   - []*Subscription[T]: The subscriptions. Nil if the broker is already
+//     closed.
+func (b *Broker[T]) detach() []*Subscription[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+
+	b.closed = true
+
+	subs := make([]*Subscription[T], 0, len(b.subs))
+
+	for _, s := range b.subs {
+		subs = append(subs, s)
+	}
+
+	b.subs = nil
+
+	return subs
+}
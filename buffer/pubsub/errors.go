@@ -0,0 +1,15 @@
+package pubsub
+
+import "errors"
+
+var (
+	// ErrBrokerClosed occurs when the broker is already closed.
+	//
+	// Format:
+	//   "broker is already closed"
+	ErrBrokerClosed error
+)
+
+func init() {
+	ErrBrokerClosed = errors.New("broker is already closed")
+}
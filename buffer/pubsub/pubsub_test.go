@@ -0,0 +1,199 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		topic   string
+		want    bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.deleted", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders", false},
+		{"orders.*", "orders.created.eu", false},
+		{"*.created", "orders.created", true},
+		{"orders.#", "orders", true},
+		{"orders.#", "orders.created.eu", true},
+		{"#", "orders.created", true},
+		{"orders.#.eu", "orders.created.eu", false},
+		{"orders..created", "orders..created", false},
+	}
+
+	for _, tt := range tests {
+		got := Match(tt.pattern, tt.topic)
+		if got != tt.want {
+			t.Errorf("Match(%q, %q): expected %t, got %t", tt.pattern, tt.topic, tt.want, got)
+		}
+	}
+}
+
+func TestBroker(t *testing.T) {
+	const (
+		MaxCount int = 100
+	)
+
+	b := new(Broker[int])
+
+	slow, err := b.Subscribe("orders.*")
+	if err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	fast, err := b.Subscribe("orders.created")
+	if err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	other, err := b.Subscribe("users.#")
+	if err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < MaxCount; i++ {
+			msg, ok := fast.Receive()
+			if !ok {
+				t.Errorf("could not receive %d", i)
+				return
+			} else if msg.Payload != i {
+				t.Errorf("expected %d, got %d", i, msg.Payload)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < MaxCount; i++ {
+		err := b.Publish("orders.created", i)
+		if err != nil {
+			t.Fatalf("could not publish %d: %v", i, err)
+		}
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the slow subscriber blocked the fast one")
+	}
+
+	other.Unsubscribe()
+
+	_, ok := other.Receive()
+	if ok {
+		t.Fatalf("expected no message after unsubscribe")
+	}
+
+	msg, ok := slow.Receive()
+	if !ok || msg.Topic != "orders.created" || msg.Payload != 0 {
+		t.Fatalf("expected %d on %q, got %d on %q", 0, "orders.created", msg.Payload, msg.Topic)
+	}
+
+	go func() {
+		for {
+			_, ok := slow.Receive()
+			if !ok {
+				return
+			}
+		}
+	}()
+
+	b.Close()
+
+	err = b.Publish("orders.created", MaxCount)
+	if err != ErrBrokerClosed {
+		t.Fatalf("expected %v, got %v", ErrBrokerClosed, err)
+	}
+}
+
+func TestRetained(t *testing.T) {
+	b := new(Broker[string])
+	defer b.Close()
+
+	err := b.PublishRetained("sensors.kitchen", "21C")
+	if err != nil {
+		t.Fatalf("could not publish: %v", err)
+	}
+
+	err = b.PublishRetained("sensors.kitchen", "22C")
+	if err != nil {
+		t.Fatalf("could not publish: %v", err)
+	}
+
+	s, err := b.Subscribe("sensors.*")
+	if err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+	defer s.Unsubscribe()
+
+	msg, ok := s.Receive()
+	if !ok || msg.Payload != "22C" {
+		t.Fatalf("expected retained %q, got %q", "22C", msg.Payload)
+	}
+
+	b.ClearRetained("sensors.kitchen")
+
+	_, ok = b.Retained("sensors.kitchen")
+	if ok {
+		t.Fatalf("expected no retained message")
+	}
+
+	_, err = b.Subscribe("sensors.#.temp")
+	if err == nil {
+		t.Fatalf("expected an invalid pattern error")
+	}
+}
+
+func TestStalledSubscriber(t *testing.T) {
+	b := new(Broker[int])
+
+	stalled, err := b.Subscribe("orders.*")
+	if err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	other, err := b.Subscribe("orders.*")
+	if err != nil {
+		t.Fatalf("could not subscribe: %v", err)
+	}
+
+	for i := range 10 {
+		_ = b.Publish("orders.created", i)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		other.Unsubscribe()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := b.CloseWithContext(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("a subscriber that stopped reading blocked the broker")
+	}
+
+	_, ok := stalled.Receive()
+	if ok {
+		t.Fatalf("expected the pending messages to be discarded")
+	}
+}
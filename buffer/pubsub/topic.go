@@ -0,0 +1,92 @@
+package pubsub
+
+import "strings"
+
+const (
+	// Separator separates the levels of a topic.
+	Separator string = "."
+
+	// SingleLevel is the wildcard that matches exactly one level of a topic.
+	SingleLevel string = "*"
+
+	// MultiLevel is the wildcard that matches every remaining level of a topic,
+	// including none. It must be the last level of a pattern.
+	MultiLevel string = "#"
+)
+
+// validTopic checks whether a topic is valid. A valid topic is not empty and
+// has no empty level nor wildcard.
+//
+// Parameters:
+//   - topic: The topic to check.
+//
+// Returns:
+//   - bool: True if the topic is valid, false otherwise.
+func validTopic(topic string) bool {
+	if topic == "" {
+		return false
+	}
+
+	for _, level := range strings.Split(topic, Separator) {
+		if level == "" || level == SingleLevel || level == MultiLevel {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validPattern checks whether a pattern is valid. A valid pattern is not empty,
+// has no empty level and only has MultiLevel as its last level.
+//
+// Parameters:
+//   - pattern: The pattern to check.
+//
+// Returns:
+//   - bool: True if the pattern is valid, false otherwise.
+func validPattern(pattern string) bool {
+	if pattern == "" {
+		return false
+	}
+
+	levels := strings.Split(pattern, Separator)
+
+	for i, level := range levels {
+		if level == "" || (level == MultiLevel && i != len(levels)-1) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Match checks whether a topic matches a pattern. The levels of the pattern
+// match the levels of the topic one by one, where SingleLevel matches any level
+// and MultiLevel matches every remaining level.
+//
+// Parameters:
+//   - pattern: The pattern, such as "orders.*" or "orders.#".
+//   - topic: The topic, such as "orders.created".
+//
+// Returns:
+//   - bool: True if the topic matches the pattern, false otherwise.
+func Match(pattern, topic string) bool {
+	if !validPattern(pattern) || !validTopic(topic) {
+		return false
+	}
+
+	patterns := strings.Split(pattern, Separator)
+	levels := strings.Split(topic, Separator)
+
+	for i, p := range patterns {
+		if p == MultiLevel {
+			return true
+		} else if i >= len(levels) {
+			return false
+		} else if p != SingleLevel && p != levels[i] {
+			return false
+		}
+	}
+
+	return len(patterns) == len(levels)
+}
@@ -0,0 +1,214 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	internal "github.com/PlayerR9/go-safe/buffer/internal"
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// lastCallID is the correlation ID of the last Call.
+var lastCallID atomic.Uint64
+
+// reply is the reply to a Call.
+type reply[Resp any] struct {
+	// resp is the response of the handler.
+	resp Resp
+
+	// err is the error of the handler.
+	err error
+}
+
+// Call is a request sent with Request and waiting for its reply. The buffer
+// that carries the requests of type Req with responses of type Resp is created
+// with the `NewContext[*Call[Req, Resp]](parent)` constructor.
+type Call[Req, Resp any] struct {
+	// id is the correlation ID of the call.
+	id uint64
+
+	// req is the request.
+	req Req
+
+	// ctx is the context of the requester.
+	ctx context.Context
+
+	// replyTo is the channel the reply is sent to. It is buffered so that the
+	// handler never blocks on a requester that gave up.
+	replyTo chan reply[Resp]
+}
+
+// ID returns the correlation ID of the call.
+//
+// Returns:
+//   - uint64: The correlation ID. 0 if the receiver is nil.
+func (c *Call[Req, Resp]) ID() uint64 {
+	if c == nil {
+		return 0
+	}
+
+	return c.id
+}
+
+// Request returns the request of the call.
+//
+// Returns:
+//   - Req: The request. The zero value if the receiver is nil.
+func (c *Call[Req, Resp]) Request() Req {
+	if c == nil {
+		return *new(Req)
+	}
+
+	return c.req
+}
+
+// requestAct is an action that sends a request and waits for its reply.
+type requestAct[Req, Resp any] struct {
+	// msg is the request.
+	msg Req
+
+	// dest is the destination of the response.
+	dest *Resp
+}
+
+// Run implements the common.Action interface.
+func (act *requestAct[Req, Resp]) Run(ctx context.Context) error {
+	c, err := fromContext[*Call[Req, Resp]](ctx)
+	if err != nil {
+		return err
+	}
+
+	call := &Call[Req, Resp]{
+		id:      lastCallID.Add(1),
+		req:     act.msg,
+		ctx:     ctx,
+		replyTo: make(chan reply[Resp], 1),
+	}
+
+	err = c.buffer.Send(call)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case r := <-call.replyTo:
+		if r.err != nil {
+			return r.err
+		}
+
+		*act.dest = r.resp
+
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Request sends a request to the buffer of calls carried by the context and
+// waits for the reply of the handler run by Serve. The request is abandoned
+// when the context is done, and the context of the handler is done as well.
+//
+// Parameters:
+//   - msg: The request.
+//   - dest: The destination of the response.
+//
+// Returns:
+//   - common.Action: The request action. Nil if dest is nil.
+func Request[Req, Resp any](msg Req, dest *Resp) common.Action {
+	if dest == nil {
+		return nil
+	}
+
+	return &requestAct[Req, Resp]{
+		msg:  msg,
+		dest: dest,
+	}
+}
+
+// serveAct is an action that serves the requests of a buffer of calls.
+type serveAct[Req, Resp any] struct {
+	// handler is the handler of the requests.
+	handler func(ctx context.Context, req Req) (Resp, error)
+}
+
+// serve runs the handler on a call and replies to it.
+//
+// Parameters:
+//   - ctx: The context of Serve.
+//   - call: The call to serve.
+func (act *serveAct[Req, Resp]) serve(ctx context.Context, call *Call[Req, Resp]) {
+	hctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if deadline, ok := call.ctx.Deadline(); ok {
+		hctx, cancel = context.WithDeadline(hctx, deadline)
+		defer cancel()
+	}
+
+	stop := context.AfterFunc(call.ctx, cancel)
+	defer stop()
+
+	resp, err := act.handler(hctx, call.req)
+
+	call.replyTo <- reply[Resp]{
+		resp: resp,
+		err:  err,
+	}
+}
+
+// Run implements the common.Action interface.
+func (act *serveAct[Req, Resp]) Run(ctx context.Context) error {
+	c, err := fromContext[*Call[Req, Resp]](ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		call, err := c.buffer.ReceiveWithContext(ctx)
+		if errors.Is(err, internal.ErrAlreadyClosed) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			call.replyTo <- reply[Resp]{
+				err: ctx.Err(),
+			}
+
+			return ctx.Err()
+		}
+
+		if call.ctx.Err() != nil {
+			// The requester gave up.
+			continue
+		}
+
+		act.serve(ctx, call)
+	}
+}
+
+// Serve receives the requests of the buffer of calls carried by the context and
+// replies to each of them with the response or the error of the handler. The
+// handler is given a context that is done when the requester gives up or its
+// deadline passes.
+//
+// Requests are served one at a time; run Serve in several goroutines to serve
+// them concurrently. It returns once the buffer is closed or the context is
+// done.
+//
+// Parameters:
+//   - handler: The handler of the requests.
+//
+// Returns:
+//   - common.Action: The serve action. Nil if handler is nil.
+func Serve[Req, Resp any](handler func(ctx context.Context, req Req) (Resp, error)) common.Action {
+	if handler == nil {
+		return nil
+	}
+
+	return &serveAct[Req, Resp]{
+		handler: handler,
+	}
+}
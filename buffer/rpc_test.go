@@ -0,0 +1,103 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+func TestRequest(t *testing.T) {
+	ctx, cancel := NewContext[*Call[int, int]](context.Background())
+
+	errNegative := errors.New("negative request")
+
+	handler := func(ctx context.Context, req int) (int, error) {
+		switch {
+		case req < 0:
+			return 0, errNegative
+		case req == 0:
+			<-ctx.Done()
+			return 0, ctx.Err()
+		default:
+			return 2 * req, nil
+		}
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		// The buffer is closed right before the context is cancelled, so
+		// either can end Serve.
+		err := common.Run(ctx, Serve(handler))
+		if err != nil && !errors.Is(err, context.Canceled) {
+			t.Errorf("could not serve: %v", err)
+		}
+	}()
+
+	var resp int
+
+	err := common.Run(ctx, Request(21, &resp))
+	if err != nil {
+		t.Fatalf("could not request: %v", err)
+	} else if resp != 42 {
+		t.Fatalf("expected %d, got %d", 42, resp)
+	}
+
+	err = common.Run(ctx, Request(-1, &resp))
+	if !errors.Is(err, errNegative) {
+		t.Fatalf("expected the handler error, got %v", err)
+	}
+
+	tctx, tcancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer tcancel()
+
+	err = common.Run(tctx, Request(0, &resp))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the request to time out, got %v", err)
+	}
+
+	err = common.Run(ctx, Request(1, &resp))
+	if err != nil {
+		t.Fatalf("could not request: %v", err)
+	} else if resp != 2 {
+		t.Fatalf("expected %d, got %d", 2, resp)
+	}
+
+	cancel()
+
+	wg.Wait()
+}
+
+func TestServeCancel(t *testing.T) {
+	ctx, cancel := NewContext[*Call[int, int]](context.Background())
+	defer cancel()
+
+	sctx, scancel := context.WithCancel(ctx)
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- common.Run(sctx, Serve(func(ctx context.Context, req int) (int, error) {
+			return req, nil
+		}))
+	}()
+
+	scancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected %v, got %v", context.Canceled, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected Serve to return once its context is done")
+	}
+}
@@ -0,0 +1,250 @@
+package buffer
+
+import (
+	"context"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// handler handles the value received by reflect.Select for a case.
+//
+// Parameters:
+//   - v: The value received by reflect.Select.
+//   - ok: Whether the value was received.
+//
+// Returns:
+//   - error: An error if the case fails to run.
+type handler func(v reflect.Value, ok bool) error
+
+// poller receives a message that is ready, without waiting, and handles it.
+//
+// Returns:
+//   - bool: True if a message was received, false otherwise.
+//   - error: An error if the case fails to run.
+type poller func() (bool, error)
+
+// Case is a case of Select.
+type Case interface {
+	// prepare returns the case of reflect.Select along with the handler that
+	// runs once it is chosen.
+	//
+	// Parameters:
+	//   - ctx: The context Select runs in.
+	//
+	// Returns:
+	//   - reflect.SelectCase: The case.
+	//   - handler: The handler of the case.
+	//   - poller: The poller of the case, tried before the default case runs.
+	//     Nil if the case cannot be polled.
+	//   - error: An error if the case cannot be prepared.
+	prepare(ctx context.Context) (reflect.SelectCase, handler, poller, error)
+}
+
+// recvCase is a case that receives a message from a buffer.
+type recvCase[T any] struct {
+	// src is the context that carries the buffer. If nil, the context Select
+	// runs in is used.
+	src context.Context
+
+	// fn is the function that creates the action that handles the message.
+	fn func(msg T) common.Action
+}
+
+// prepare implements the Case interface.
+func (rc *recvCase[T]) prepare(ctx context.Context) (reflect.SelectCase, handler, poller, error) {
+	src := rc.src
+	if src == nil {
+		src = ctx
+	}
+
+	c, err := fromContext[T](src)
+	if err != nil {
+		return reflect.SelectCase{}, nil, nil, err
+	}
+
+	h := func(v reflect.Value, ok bool) error {
+		msg, err := c.buffer.Accept(v, ok)
+		if err != nil {
+			return err
+		}
+
+		return common.Run(ctx, rc.fn(msg))
+	}
+
+	p := func() (bool, error) {
+		msg, ok := c.buffer.TryReceive()
+		if !ok {
+			return false, nil
+		}
+
+		return true, common.Run(ctx, rc.fn(msg))
+	}
+
+	return c.buffer.SelectCase(), h, p, nil
+}
+
+// On creates a case of Select that receives a message from the buffer of type
+// T carried by the context Select runs in.
+//
+// Parameters:
+//   - fn: The function that creates the action that handles the message.
+//
+// Returns:
+//   - Case: The case. Nil if fn is nil.
+func On[T any](fn func(msg T) common.Action) Case {
+	if fn == nil {
+		return nil
+	}
+
+	return &recvCase[T]{
+		fn: fn,
+	}
+}
+
+// OnFrom creates a case of Select that receives a message from the buffer of
+// type T carried by another context. It allows to select across buffers of the
+// same type.
+//
+// Parameters:
+//   - src: The context that carries the buffer. If nil, the context Select runs
+//     in is used.
+//   - fn: The function that creates the action that handles the message.
+//
+// Returns:
+//   - Case: The case. Nil if fn is nil.
+func OnFrom[T any](src context.Context, fn func(msg T) common.Action) Case {
+	if fn == nil {
+		return nil
+	}
+
+	return &recvCase[T]{
+		src: src,
+		fn:  fn,
+	}
+}
+
+// defaultCase is the case that runs when no buffer is ready.
+type defaultCase struct {
+	// act is the action to run.
+	act common.Action
+}
+
+// prepare implements the Case interface.
+func (dc *defaultCase) prepare(ctx context.Context) (reflect.SelectCase, handler, poller, error) {
+	sc := reflect.SelectCase{
+		Dir: reflect.SelectDefault,
+	}
+
+	h := func(v reflect.Value, ok bool) error {
+		return common.Run(ctx, dc.act)
+	}
+
+	return sc, h, nil, nil
+}
+
+// Default creates the case of Select that runs when no buffer has a message
+// ready. At most one default case can be given to Select.
+//
+// Parameters:
+//   - act: The action to run. If nil, nothing is run.
+//
+// Returns:
+//   - Case: The case. Never returns nil.
+func Default(act common.Action) Case {
+	return &defaultCase{
+		act: act,
+	}
+}
+
+// selectAct is an action that waits on several buffers.
+type selectAct struct {
+	// cases are the cases of the select.
+	cases []Case
+}
+
+// Run implements the common.Action interface.
+func (act *selectAct) Run(ctx context.Context) error {
+	if ctx == nil {
+		return common.NewErrNilParam("ctx")
+	}
+
+	scs := make([]reflect.SelectCase, 0, len(act.cases)+1)
+	handlers := make([]handler, 0, len(act.cases))
+	pollers := make([]poller, 0, len(act.cases))
+
+	scs = append(scs, reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(ctx.Done()),
+	})
+
+	for _, c := range act.cases {
+		sc, h, p, err := c.prepare(ctx)
+		if err != nil {
+			return err
+		}
+
+		scs = append(scs, sc)
+		handlers = append(handlers, h)
+
+		if p != nil {
+			pollers = append(pollers, p)
+		}
+	}
+
+	chosen, v, ok := reflect.Select(scs)
+	if chosen == 0 {
+		return ctx.Err()
+	}
+
+	if scs[chosen].Dir == reflect.SelectDefault {
+		// The buffers only hand off their pending messages to a receiver that
+		// is already waiting, so they are polled before giving up on them.
+		for _, i := range rand.Perm(len(pollers)) {
+			ok, err := pollers[i]()
+			if ok {
+				return err
+			}
+		}
+	}
+
+	return handlers[chosen-1](v, ok)
+}
+
+// Select waits until one of the cases is ready and runs it, like Go's select
+// statement: if several buffers have a message ready, one of them is chosen at
+// random, and the default case, if any, runs when none is ready. Only the
+// message of the chosen buffer is received.
+//
+// Select returns the error of the context if it is done first, and the close
+// reason of a buffer if the chosen buffer is closed.
+//
+// Parameters:
+//   - cases: The cases. Nil cases are ignored.
+//
+// Returns:
+//   - common.Action: The select action. Nil if there is no case or more than
+//     one default case.
+func Select(cases ...Case) common.Action {
+	var defaults int
+
+	cases = slices.DeleteFunc(slices.Clone(cases), func(c Case) bool {
+		return c == nil
+	})
+
+	for _, c := range cases {
+		if _, ok := c.(*defaultCase); ok {
+			defaults++
+		}
+	}
+
+	if len(cases) == 0 || defaults > 1 {
+		return nil
+	}
+
+	return &selectAct{
+		cases: cases,
+	}
+}
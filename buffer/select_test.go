@@ -0,0 +1,156 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+func TestSelect(t *testing.T) {
+	ints, cancelInts := NewContext[int](context.Background())
+	defer cancelInts()
+
+	ctx, cancel := NewContext[string](ints)
+	defer cancel()
+
+	others, cancelOthers := NewContext[int](context.Background())
+	defer cancelOthers()
+
+	var (
+		gotInt    int
+		gotString string
+		gotOther  int
+		ran       bool
+	)
+
+	sel := Select(
+		On(func(msg int) common.Action {
+			gotInt = msg
+			return nil
+		}),
+		On(func(msg string) common.Action {
+			gotString = msg
+			return nil
+		}),
+		OnFrom(others, func(msg int) common.Action {
+			gotOther = msg
+			return nil
+		}),
+	)
+
+	err := Send("hello").Run(ctx)
+	if err != nil {
+		t.Fatalf("could not send: %v", err)
+	}
+
+	err = sel.Run(ctx)
+	if err != nil {
+		t.Fatalf("could not select: %v", err)
+	} else if gotString != "hello" || gotInt != 0 || gotOther != 0 {
+		t.Fatalf("expected only the string case to run")
+	}
+
+	err = Send(42).Run(others)
+	if err != nil {
+		t.Fatalf("could not send: %v", err)
+	}
+
+	err = sel.Run(ctx)
+	if err != nil {
+		t.Fatalf("could not select: %v", err)
+	} else if gotOther != 42 || gotInt != 0 {
+		t.Fatalf("expected only the other case to run")
+	}
+
+	withDefault := Select(
+		On(func(msg int) common.Action {
+			gotInt = msg
+			return nil
+		}),
+		Default(nil),
+	)
+
+	err = withDefault.Run(ctx)
+	if err != nil {
+		t.Fatalf("could not select: %v", err)
+	} else if gotInt != 0 {
+		t.Fatalf("expected the default case to run")
+	}
+
+	if Select(Default(nil), Default(nil)) != nil {
+		t.Fatalf("expected nil action for two default cases")
+	}
+
+	tctx, tcancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer tcancel()
+
+	err = Select(On(func(msg int) common.Action {
+		ran = true
+		return nil
+	})).Run(tctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	} else if ran {
+		t.Fatalf("expected no case to run")
+	}
+}
+
+// countAct is an action that counts how many times it runs.
+type countAct struct {
+	n *int
+}
+
+// Run implements the common.Action interface.
+func (ca countAct) Run(ctx context.Context) error {
+	*ca.n++
+	return nil
+}
+
+func TestSelectDefaultPending(t *testing.T) {
+	const MaxCount int = 100
+
+	ctx, cancel := NewContext[int](context.Background())
+	defer cancel()
+
+	for i := range MaxCount {
+		err := Send(i).Run(ctx)
+		if err != nil {
+			t.Fatalf("could not send: %v", err)
+		}
+	}
+
+	for {
+		stats, err := StatsOf[int](ctx)
+		if err != nil {
+			t.Fatalf("could not get the stats: %v", err)
+		} else if stats.Depth == MaxCount {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	var received, defaults int
+
+	sel := Select(
+		On(func(msg int) common.Action {
+			received++
+			return nil
+		}),
+		Default(countAct{&defaults}),
+	)
+
+	for range MaxCount {
+		err := sel.Run(ctx)
+		if err != nil {
+			t.Fatalf("could not select: %v", err)
+		}
+	}
+
+	if received != MaxCount || defaults != 0 {
+		t.Fatalf("expected %d messages and no default, got %d messages and %d defaults", MaxCount, received, defaults)
+	}
+}
@@ -0,0 +1,96 @@
+package buffer
+
+import (
+	"context"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// Snapshot encodes the pending messages of the buffer carried by the context
+// in a snapshot that Restore adds to a buffer. Like queue.Queue.Snapshot, it
+// leaves the buffer unchanged; use Drain to move the messages to another
+// buffer without duplicating them. In-flight messages are not part of the
+// snapshot.
+//
+// Parameters:
+//   - ctx: The context created by NewContext.
+//   - codec: The codec of the messages, such as common.GobCodec[T]{}.
+//
+// Returns:
+//   - []byte: The snapshot.
+//   - error: An error if the snapshot could not be taken.
+func Snapshot[T any](ctx context.Context, codec common.Codec[T]) ([]byte, error) {
+	c, err := fromContext[T](ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.buffer.Snapshot(codec)
+}
+
+// Drain takes the pending messages out of the buffer carried by the context
+// and encodes them in a snapshot, so that they can be moved to another buffer
+// with Restore without being lost nor duplicated. Nothing is taken out if a
+// message cannot be encoded.
+//
+// Unlike Snapshot, it leaves the buffer without pending messages. In-flight
+// messages are not part of the snapshot, and the messages sent while the
+// snapshot is taken may or may not be; stop the senders first to move every
+// message.
+//
+// Parameters:
+//   - ctx: The context created by NewContext.
+//   - codec: The codec of the messages, such as common.GobCodec[T]{}.
+//
+// Returns:
+//   - []byte: The snapshot.
+//   - error: An error if the snapshot could not be taken.
+func Drain[T any](ctx context.Context, codec common.Codec[T]) ([]byte, error) {
+	c, err := fromContext[T](ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.buffer.Drain(codec)
+}
+
+// restoreAct is an action that restores a snapshot in the Buffer.
+type restoreAct[T any] struct {
+	// data is the snapshot.
+	data []byte
+
+	// codec is the codec of the messages.
+	codec common.Codec[T]
+}
+
+// Run implements the common.Action interface.
+func (act *restoreAct[T]) Run(ctx context.Context) error {
+	c, err := fromContext[T](ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.buffer.Restore(act.data, act.codec)
+}
+
+// Restore adds the messages of a snapshot taken with Snapshot or Drain to the
+// Buffer. Delayed messages stay invisible until their due time, and the
+// messages keep their expiration time and failed attempts. Nothing is added if
+// the snapshot cannot be decoded.
+//
+// Parameters:
+//   - data: The snapshot.
+//   - codec: The codec of the messages.
+//
+// Returns:
+//   - common.Action: The restore action. Nil if codec is nil.
+func Restore[T any](data []byte, codec common.Codec[T]) common.Action {
+	if codec == nil {
+		return nil
+	}
+
+	return &restoreAct[T]{
+		data:  data,
+		codec: codec,
+	}
+}
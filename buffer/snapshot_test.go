@@ -0,0 +1,140 @@
+package buffer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+func TestDrain(t *testing.T) {
+	const (
+		MaxCount int = 10
+	)
+
+	clock := common.NewManualClock(time.Unix(0, 0))
+
+	old, cancelOld := NewContext[int](context.Background(), WithClock[int](clock))
+
+	for i := 0; i < MaxCount; i++ {
+		err := Send(i).Run(old)
+		if err != nil {
+			t.Fatalf("could not send %d: %v", i, err)
+		}
+	}
+
+	err := SendAfter(MaxCount, time.Minute).Run(old)
+	if err != nil {
+		t.Fatalf("could not send %d: %v", MaxCount, err)
+	}
+
+	// Wait for the messages to leave the send channel.
+	for {
+		stats, err := StatsOf[int](old)
+		if err != nil {
+			t.Fatalf("could not get stats: %v", err)
+		} else if stats.Depth == MaxCount {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	data, err := Drain[int](old, common.GobCodec[int]{})
+	if err != nil {
+		t.Fatalf("could not take snapshot: %v", err)
+	}
+
+	// The old buffer is empty, so closing it does not wait for any receiver.
+	cancelOld()
+
+	ctx, cancel := NewContext[int](context.Background(), WithClock[int](clock))
+	defer cancel()
+
+	err = Restore(data, common.GobCodec[int]{}).Run(ctx)
+	if err != nil {
+		t.Fatalf("could not restore snapshot: %v", err)
+	}
+
+	for i := 0; i < MaxCount; i++ {
+		var x int
+
+		err := Receive(&x).Run(ctx)
+		if err != nil {
+			t.Fatalf("could not receive %d: %v", i, err)
+		} else if x != i {
+			t.Fatalf("expected %d, got %d", i, x)
+		}
+	}
+
+	clock.Advance(time.Minute)
+
+	var x int
+
+	err = Receive(&x).Run(ctx)
+	if err != nil {
+		t.Fatalf("could not receive delayed message: %v", err)
+	} else if x != MaxCount {
+		t.Fatalf("expected %d, got %d", MaxCount, x)
+	}
+
+	err = Restore(data[:len(data)-1], common.GobCodec[int]{}).Run(ctx)
+	if err == nil {
+		t.Fatalf("expected an error for a truncated snapshot")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	const (
+		MaxCount int = 10
+	)
+
+	old, cancelOld := NewContext[int](context.Background())
+	defer cancelOld()
+
+	for i := 0; i < MaxCount; i++ {
+		err := Send(i).Run(old)
+		if err != nil {
+			t.Fatalf("could not send %d: %v", i, err)
+		}
+	}
+
+	// Wait for the messages to leave the send channel.
+	for {
+		stats, err := StatsOf[int](old)
+		if err != nil {
+			t.Fatalf("could not get stats: %v", err)
+		} else if stats.Depth == MaxCount {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	data, err := Snapshot[int](old, common.GobCodec[int]{})
+	if err != nil {
+		t.Fatalf("could not take snapshot: %v", err)
+	}
+
+	ctx, cancel := NewContext[int](context.Background())
+	defer cancel()
+
+	err = Restore(data, common.GobCodec[int]{}).Run(ctx)
+	if err != nil {
+		t.Fatalf("could not restore snapshot: %v", err)
+	}
+
+	for _, c := range []context.Context{old, ctx} {
+		for i := 0; i < MaxCount; i++ {
+			var x int
+
+			err := Receive(&x).Run(c)
+			if err != nil {
+				t.Fatalf("could not receive %d: %v", i, err)
+			} else if x != i {
+				t.Fatalf("expected %d, got %d", i, x)
+			}
+		}
+	}
+}
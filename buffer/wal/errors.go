@@ -0,0 +1,21 @@
+package wal
+
+import "errors"
+
+var (
+	// ErrClosed occurs when the log is already closed.
+	//
+	// Format:
+	//   "log is already closed"
+	ErrClosed error
+
+	// errCorrupted occurs when a record fails its checksum or is truncated. It
+	// marks the end of the readable part of a segment.
+	errCorrupted error
+)
+
+func init() {
+	ErrClosed = errors.New("log is already closed")
+
+	errCorrupted = errors.New("corrupted record")
+}
@@ -0,0 +1,97 @@
+package wal
+
+import (
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// SyncPolicy is the policy that decides when the log is flushed to stable
+// storage.
+type SyncPolicy int
+
+const (
+	// SyncAlways flushes the log after every record. It is the safest and
+	// slowest policy.
+	SyncAlways SyncPolicy = iota
+
+	// SyncInterval flushes the log periodically. The records written since the
+	// last flush may be lost if the machine crashes.
+	SyncInterval
+
+	// SyncNever leaves the flushing to the operating system. The records
+	// survive a crash of the process but not a crash of the machine.
+	SyncNever
+)
+
+const (
+	// DefaultSegmentSize is the default size above which a segment is sealed
+	// and a new one is started.
+	DefaultSegmentSize int64 = 4 << 20
+
+	// DefaultSyncInterval is the default interval of the SyncInterval policy.
+	DefaultSyncInterval time.Duration = time.Second
+)
+
+// config is the configuration of a Log.
+type config[T any] struct {
+	// codec is the codec of the messages.
+	codec common.Codec[T]
+
+	// policy is the sync policy.
+	policy SyncPolicy
+
+	// interval is the interval of the SyncInterval policy.
+	interval time.Duration
+
+	// segmentSize is the size above which a segment is sealed.
+	segmentSize int64
+}
+
+// Option is an option of Open.
+type Option[T any] func(cfg *config[T])
+
+// WithCodec sets the codec of the messages. common.GobCodec is used by default.
+//
+// Parameters:
+//   - codec: The codec. If nil, common.GobCodec is used.
+//
+// Returns:
+//   - Option[T]: The option. Never returns nil.
+func WithCodec[T any](codec common.Codec[T]) Option[T] {
+	return func(cfg *config[T]) {
+		cfg.codec = codec
+	}
+}
+
+// WithSyncPolicy sets the sync policy of the log. SyncAlways is used by
+// default.
+//
+// Parameters:
+//   - policy: The sync policy.
+//   - interval: The interval of the SyncInterval policy. If it is not positive,
+//     DefaultSyncInterval is used. Ignored by the other policies.
+//
+// Returns:
+//   - Option[T]: The option. Never returns nil.
+func WithSyncPolicy[T any](policy SyncPolicy, interval time.Duration) Option[T] {
+	return func(cfg *config[T]) {
+		cfg.policy = policy
+		cfg.interval = interval
+	}
+}
+
+// WithSegmentSize sets the size above which a segment is sealed and a new one
+// is started. Only sealed segments can be compacted.
+//
+// Parameters:
+//   - size: The size in bytes. If it is not positive, DefaultSegmentSize is
+//     used.
+//
+// Returns:
+//   - Option[T]: The option. Never returns nil.
+func WithSegmentSize[T any](size int64) Option[T] {
+	return func(cfg *config[T]) {
+		cfg.segmentSize = size
+	}
+}
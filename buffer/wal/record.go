@@ -0,0 +1,114 @@
+package wal
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+// recordType is the type of a record of the log.
+type recordType byte
+
+const (
+	// recSend is the record of an appended message.
+	recSend recordType = iota + 1
+
+	// recAck is the record of an acknowledged message.
+	recAck
+)
+
+const (
+	// headerSize is the size of the header of a record: its type, its sequence
+	// number and the length of its payload.
+	headerSize int = 1 + 8 + 4
+
+	// checksumSize is the size of the checksum that ends a record.
+	checksumSize int = 4
+
+	// maxPayloadSize is the size above which a payload is deemed corrupted.
+	maxPayloadSize uint32 = 1 << 30
+)
+
+// record is a record of the log.
+type record struct {
+	// kind is the type of the record.
+	kind recordType
+
+	// seq is the sequence number of the message the record is about.
+	seq uint64
+
+	// payload is the encoded message. Empty for recAck records.
+	payload []byte
+}
+
+// encode encodes the record.
+//
+// Returns:
+//   - []byte: The encoded record.
+func (r record) encode() []byte {
+	data := make([]byte, headerSize+len(r.payload)+checksumSize)
+
+	data[0] = byte(r.kind)
+	binary.LittleEndian.PutUint64(data[1:9], r.seq)
+	binary.LittleEndian.PutUint32(data[9:13], uint32(len(r.payload)))
+	copy(data[headerSize:], r.payload)
+
+	end := headerSize + len(r.payload)
+	binary.LittleEndian.PutUint32(data[end:], crc32.ChecksumIEEE(data[:end]))
+
+	return data
+}
+
+// readRecord reads the next record.
+//
+// Parameters:
+//   - r: The reader to read from.
+//
+// Returns:
+//   - record: The record read.
+//   - error: io.EOF at the end of the reader, errCorrupted if the record is
+//     truncated or fails its checksum, or any other error from the reader.
+func readRecord(r io.Reader) (record, error) {
+	header := make([]byte, headerSize)
+
+	_, err := io.ReadFull(r, header)
+	if err == io.EOF {
+		return record{}, io.EOF
+	} else if err == io.ErrUnexpectedEOF {
+		return record{}, errCorrupted
+	} else if err != nil {
+		return record{}, err
+	}
+
+	kind := recordType(header[0])
+	if kind != recSend && kind != recAck {
+		return record{}, errCorrupted
+	}
+
+	size := binary.LittleEndian.Uint32(header[9:13])
+	if size > maxPayloadSize {
+		return record{}, errCorrupted
+	}
+
+	rest := make([]byte, int(size)+checksumSize)
+
+	_, err = io.ReadFull(r, rest)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return record{}, errCorrupted
+	} else if err != nil {
+		return record{}, err
+	}
+
+	checksum := crc32.ChecksumIEEE(header)
+	checksum = crc32.Update(checksum, crc32.IEEETable, rest[:size])
+
+	if checksum != binary.LittleEndian.Uint32(rest[size:]) {
+		return record{}, errCorrupted
+	}
+
+	return record{
+		kind:    kind,
+		seq:     binary.LittleEndian.Uint64(header[1:9]),
+		payload: rest[:size],
+	}, nil
+}
@@ -0,0 +1,572 @@
+package wal
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// segmentExt is the extension of the segment files.
+const segmentExt string = ".wal"
+
+// Record is a message of the log that has not been acknowledged.
+type Record[T any] struct {
+	// Seq is the sequence number of the message.
+	Seq uint64
+
+	// Msg is the message.
+	Msg T
+}
+
+// segment is a file of the log.
+type segment struct {
+	// id is the identifier of the segment. Segments are ordered by id.
+	id uint64
+
+	// pending is the number of messages of the segment that have not been
+	// acknowledged.
+	pending int
+}
+
+// Log is a write-ahead log of messages split in segment files. Every appended
+// message is written to the active segment until it is acknowledged. Sealed
+// segments whose messages, and those of every older segment, are all
+// acknowledged are deleted.
+//
+// A Log must be created with Open.
+type Log[T any] struct {
+	// dir is the directory of the segment files.
+	dir string
+
+	// cfg is the configuration of the log.
+	cfg config[T]
+
+	// segments are the segments of the log ordered by id. The last one is the
+	// active segment.
+	segments []*segment
+
+	// active is the file of the active segment. Nil once the log is closed.
+	active *os.File
+
+	// size is the size of the active segment.
+	size int64
+
+	// owners maps the sequence number of every pending message to its segment.
+	owners map[uint64]*segment
+
+	// pending are the messages that were not acknowledged when the log was
+	// opened, until they are acknowledged or handed out by Pending.
+	pending []Record[T]
+
+	// seq is the sequence number of the last appended message.
+	seq uint64
+
+	// dirty is true if records were written since the last flush.
+	dirty bool
+
+	// stop stops the background flusher of the SyncInterval policy.
+	stop chan struct{}
+
+	// wg waits for the background flusher.
+	wg sync.WaitGroup
+
+	// mu is the mutex that synchronizes the log.
+	mu sync.Mutex
+}
+
+// segmentPath returns the path of a segment file.
+//
+// Parameters:
+//   - id: The identifier of the segment.
+//
+// Returns:
+//   - string: The path of the segment file.
+func (l *Log[T]) segmentPath(id uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+// Open opens the log stored in a directory, creating the directory if needed.
+// The messages that were appended but not acknowledged are replayed and are
+// available through Pending.
+//
+// Parameters:
+//   - dir: The directory of the log.
+//   - opts: The options of the log.
+//
+// Returns:
+//   - *Log[T]: The log. Nil if an error occurred.
+//   - error: An error if the log could not be opened.
+func Open[T any](dir string, opts ...Option[T]) (*Log[T], error) {
+	var cfg config[T]
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	if cfg.codec == nil {
+		cfg.codec = common.GobCodec[T]{}
+	}
+
+	if cfg.interval <= 0 {
+		cfg.interval = DefaultSyncInterval
+	}
+
+	if cfg.segmentSize <= 0 {
+		cfg.segmentSize = DefaultSegmentSize
+	}
+
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Log[T]{
+		dir:    dir,
+		cfg:    cfg,
+		owners: make(map[uint64]*segment),
+	}
+
+	err = l.replay()
+	if err != nil {
+		return nil, err
+	}
+
+	var id uint64 = 1
+
+	if len(l.segments) > 0 {
+		id = l.segments[len(l.segments)-1].id + 1
+	}
+
+	err = l.rotate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	l.compact()
+
+	if cfg.policy == SyncInterval {
+		l.stop = make(chan struct{})
+
+		l.wg.Add(1)
+
+		go l.flushPeriodically()
+	}
+
+	return l, nil
+}
+
+// replay reads every segment of the log and collects the pending messages.
+//
+// Returns:
+//   - error: An error if a segment could not be read.
+func (l *Log[T]) replay() error {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return err
+	}
+
+	var ids []uint64
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	msgs := make(map[uint64]T)
+
+	for _, id := range ids {
+		seg := &segment{
+			id: id,
+		}
+
+		l.segments = append(l.segments, seg)
+
+		err := l.replaySegment(seg, msgs)
+		if err != nil {
+			return fmt.Errorf("could not replay segment %d: %w", id, err)
+		}
+	}
+
+	for seq, msg := range msgs {
+		l.pending = append(l.pending, Record[T]{
+			Seq: seq,
+			Msg: msg,
+		})
+	}
+
+	slices.SortFunc(l.pending, func(a, b Record[T]) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+
+	return nil
+}
+
+// replaySegment reads the records of a segment. Reading stops at the first
+// corrupted record, as it can only be the result of an interrupted write.
+//
+// Parameters:
+//   - seg: The segment to read.
+//   - msgs: The pending messages by sequence number.
+//
+// Returns:
+//   - error: An error if the segment could not be read or a message could not
+//     be decoded.
+func (l *Log[T]) replaySegment(seg *segment, msgs map[uint64]T) error {
+	f, err := os.Open(l.segmentPath(seg.id))
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+
+	for {
+		rec, err := readRecord(r)
+		if err == io.EOF || err == errCorrupted {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		l.seq = max(l.seq, rec.seq)
+
+		switch rec.kind {
+		case recSend:
+			msg, err := l.cfg.codec.Decode(rec.payload)
+			if err != nil {
+				return fmt.Errorf("could not decode message %d: %w", rec.seq, err)
+			}
+
+			msgs[rec.seq] = msg
+			l.owners[rec.seq] = seg
+			seg.pending++
+		case recAck:
+			owner, ok := l.owners[rec.seq]
+			if !ok {
+				continue
+			}
+
+			delete(msgs, rec.seq)
+			delete(l.owners, rec.seq)
+			owner.pending--
+		}
+	}
+}
+
+// rotate seals the active segment, if any, and starts a new one.
+//
+// Parameters:
+//   - id: The identifier of the new segment.
+//
+// Returns:
+//   - error: An error if the new segment could not be created.
+func (l *Log[T]) rotate(id uint64) error {
+	if l.active != nil {
+		err := l.active.Sync()
+		if err != nil {
+			return err
+		}
+
+		err = l.active.Close()
+		if err != nil {
+			return err
+		}
+
+		l.active = nil
+	}
+
+	f, err := os.OpenFile(l.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	l.active = f
+	l.size = 0
+	l.dirty = false
+	l.segments = append(l.segments, &segment{
+		id: id,
+	})
+
+	return nil
+}
+
+// compact deletes the oldest sealed segments whose messages are all
+// acknowledged. A segment is only deleted once every older segment is deleted
+// so that the acknowledgements it holds are never needed again.
+func (l *Log[T]) compact() {
+	for len(l.segments) > 1 && l.segments[0].pending == 0 {
+		_ = os.Remove(l.segmentPath(l.segments[0].id))
+
+		l.segments[0] = nil
+		l.segments = l.segments[1:]
+	}
+}
+
+// write writes a record to the active segment and flushes it according to the
+// sync policy.
+//
+// Parameters:
+//   - rec: The record to write.
+//
+// Returns:
+//   - error: An error if the record could not be written.
+func (l *Log[T]) write(rec record) error {
+	n, err := l.active.Write(rec.encode())
+	l.size += int64(n)
+
+	if err != nil {
+		return err
+	}
+
+	if l.cfg.policy == SyncAlways {
+		err := l.active.Sync()
+		if err != nil {
+			return err
+		}
+	} else {
+		l.dirty = true
+	}
+
+	if l.size < l.cfg.segmentSize {
+		return nil
+	}
+
+	return l.rotate(l.segments[len(l.segments)-1].id + 1)
+}
+
+// Pending hands out the messages that were not acknowledged when the log was
+// opened and are still not acknowledged. They are handed out only once, to the
+// buffer that replays them, so that a second buffer built on the same log does
+// not deliver them again.
+//
+// Returns:
+//   - []Record[T]: The pending messages ordered by sequence number. Nil if the
+//     receiver is nil or they were already handed out.
+func (l *Log[T]) Pending() []Record[T] {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	pending := l.pending
+	l.pending = nil
+
+	return pending
+}
+
+// Append appends a message to the log.
+//
+// Parameters:
+//   - msg: The message to append.
+//
+// Returns:
+//   - uint64: The sequence number of the message. Never zero.
+//   - error: An error if the message could not be appended.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrClosed: If the log is closed.
+//   - any other error: If the message could not be encoded or written.
+func (l *Log[T]) Append(msg T) (uint64, error) {
+	if l == nil {
+		return 0, common.ErrNilReceiver
+	}
+
+	payload, err := l.cfg.codec.Encode(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active == nil {
+		return 0, ErrClosed
+	}
+
+	seq := l.seq + 1
+	seg := l.segments[len(l.segments)-1]
+
+	err = l.write(record{
+		kind:    recSend,
+		seq:     seq,
+		payload: payload,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	l.seq = seq
+	l.owners[seq] = seg
+	seg.pending++
+
+	return seq, nil
+}
+
+// Ack acknowledges a message so that it is never replayed again. Does nothing
+// if the message is not pending.
+//
+// Parameters:
+//   - seq: The sequence number of the message.
+//
+// Returns:
+//   - error: An error if the acknowledgement could not be written.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrClosed: If the log is closed.
+//   - any other error: If the acknowledgement could not be written.
+func (l *Log[T]) Ack(seq uint64) error {
+	if l == nil {
+		return common.ErrNilReceiver
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active == nil {
+		return ErrClosed
+	}
+
+	owner, ok := l.owners[seq]
+	if !ok {
+		return nil
+	}
+
+	err := l.write(record{
+		kind: recAck,
+		seq:  seq,
+	})
+	if err != nil {
+		return err
+	}
+
+	delete(l.owners, seq)
+	owner.pending--
+
+	i, ok := slices.BinarySearchFunc(l.pending, seq, func(rec Record[T], seq uint64) int {
+		return cmp.Compare(rec.Seq, seq)
+	})
+	if ok {
+		l.pending = slices.Delete(l.pending, i, i+1)
+	}
+
+	l.compact()
+
+	return nil
+}
+
+// Sync flushes the active segment to stable storage.
+//
+// Returns:
+//   - error: An error if the segment could not be flushed.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrClosed: If the log is closed.
+func (l *Log[T]) Sync() error {
+	if l == nil {
+		return common.ErrNilReceiver
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active == nil {
+		return ErrClosed
+	}
+
+	if !l.dirty {
+		return nil
+	}
+
+	err := l.active.Sync()
+	if err != nil {
+		return err
+	}
+
+	l.dirty = false
+
+	return nil
+}
+
+// flushPeriodically flushes the log at the interval of the SyncInterval
+// policy until the log is closed.
+//
+// It must be run in a separate goroutine to avoid blocking the main thread.
+func (l *Log[T]) flushPeriodically() {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(l.cfg.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = l.Sync()
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// Close flushes and closes the log. Does nothing if the log is already closed.
+//
+// Returns:
+//   - error: An error if the log could not be flushed or closed.
+func (l *Log[T]) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+
+	if l.active == nil {
+		l.mu.Unlock()
+		return nil
+	}
+
+	f := l.active
+	l.active = nil
+
+	l.mu.Unlock()
+
+	if l.stop != nil {
+		close(l.stop)
+		l.wg.Wait()
+	}
+
+	err := f.Sync()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
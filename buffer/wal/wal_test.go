@@ -0,0 +1,177 @@
+package wal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	log, err := Open(dir, WithCodec[string](common.JSONCodec[string]{}))
+	if err != nil {
+		t.Fatalf("could not open the log: %v", err)
+	}
+
+	var seqs []uint64
+
+	for _, msg := range []string{"a", "b", "c"} {
+		seq, err := log.Append(msg)
+		if err != nil {
+			t.Fatalf("could not append %q: %v", msg, err)
+		}
+
+		seqs = append(seqs, seq)
+	}
+
+	err = log.Ack(seqs[1])
+	if err != nil {
+		t.Fatalf("could not ack: %v", err)
+	}
+
+	err = log.Close()
+	if err != nil {
+		t.Fatalf("could not close the log: %v", err)
+	}
+
+	log, err = Open(dir, WithCodec[string](common.JSONCodec[string]{}))
+	if err != nil {
+		t.Fatalf("could not reopen the log: %v", err)
+	}
+
+	defer log.Close()
+
+	pending := log.Pending()
+
+	expected := []Record[string]{
+		{Seq: seqs[0], Msg: "a"},
+		{Seq: seqs[2], Msg: "c"},
+	}
+
+	if len(pending) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, pending)
+	}
+
+	for i, rec := range expected {
+		if pending[i] != rec {
+			t.Fatalf("expected %v, got %v", expected, pending)
+		}
+	}
+
+	again := log.Pending()
+	if again != nil {
+		t.Fatalf("expected the pending messages to be handed out once, got %v", again)
+	}
+
+	seq, err := log.Append("d")
+	if err != nil {
+		t.Fatalf("could not append: %v", err)
+	} else if seq <= seqs[2] {
+		t.Fatalf("expected sequence numbers to keep increasing, got %d", seq)
+	}
+
+	err = log.Ack(seqs[0])
+	if err != nil {
+		t.Fatalf("could not ack: %v", err)
+	}
+
+	err = log.Close()
+	if err != nil {
+		t.Fatalf("could not close the log: %v", err)
+	}
+
+	log, err = Open(dir, WithCodec[string](common.JSONCodec[string]{}))
+	if err != nil {
+		t.Fatalf("could not reopen the log: %v", err)
+	}
+
+	defer log.Close()
+
+	err = log.Ack(seqs[2])
+	if err != nil {
+		t.Fatalf("could not ack: %v", err)
+	}
+
+	pending = log.Pending()
+	if len(pending) != 1 || pending[0].Msg != "d" {
+		t.Fatalf("expected the acknowledged messages to be left out, got %v", pending)
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	dir := t.TempDir()
+
+	log, err := Open(dir, WithSegmentSize[int](64))
+	if err != nil {
+		t.Fatalf("could not open the log: %v", err)
+	}
+
+	defer log.Close()
+
+	for i := 0; i < 20; i++ {
+		seq, err := log.Append(i)
+		if err != nil {
+			t.Fatalf("could not append %d: %v", i, err)
+		}
+
+		err = log.Ack(seq)
+		if err != nil {
+			t.Fatalf("could not ack %d: %v", i, err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatalf("could not list the segments: %v", err)
+	} else if len(files) != 1 {
+		t.Fatalf("expected only the active segment to remain, got %d segments", len(files))
+	}
+}
+
+func TestTruncatedTail(t *testing.T) {
+	dir := t.TempDir()
+
+	log, err := Open[int](dir)
+	if err != nil {
+		t.Fatalf("could not open the log: %v", err)
+	}
+
+	_, err = log.Append(42)
+	if err != nil {
+		t.Fatalf("could not append: %v", err)
+	}
+
+	err = log.Close()
+	if err != nil {
+		t.Fatalf("could not close the log: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected a single segment, got %v (%v)", files, err)
+	}
+
+	f, err := os.OpenFile(files[0], os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("could not open the segment: %v", err)
+	}
+
+	// A record whose write was interrupted.
+	_, _ = f.Write([]byte{byte(recSend), 2, 0, 0})
+	_ = f.Close()
+
+	log, err = Open[int](dir)
+	if err != nil {
+		t.Fatalf("could not reopen the log: %v", err)
+	}
+
+	defer log.Close()
+
+	pending := log.Pending()
+	if len(pending) != 1 || pending[0].Msg != 42 {
+		t.Fatalf("expected the intact record to be replayed, got %v", pending)
+	}
+}
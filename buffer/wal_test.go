@@ -0,0 +1,60 @@
+package buffer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PlayerR9/go-safe/buffer/wal"
+	"github.com/PlayerR9/go-safe/common"
+)
+
+func TestWAL(t *testing.T) {
+	dir := t.TempDir()
+
+	log, err := wal.Open[int](dir)
+	if err != nil {
+		t.Fatalf("could not open the log: %v", err)
+	}
+
+	ctx, cancel := NewContext[int](context.Background(), WithWAL(log))
+
+	err = common.Run(ctx, Send(1), Send(2))
+	if err != nil {
+		t.Fatalf("could not send: %v", err)
+	}
+
+	var x int
+
+	err = common.Run(ctx, Receive(&x))
+	if err != nil || x != 1 {
+		t.Fatalf("expected %d, got %d (%v)", 1, x, err)
+	}
+
+	// The process goes away before the second message is consumed.
+	err = log.Close()
+	if err != nil {
+		t.Fatalf("could not close the log: %v", err)
+	}
+
+	err = common.Run(ctx, Receive(&x))
+	if err != nil {
+		t.Fatalf("could not receive: %v", err)
+	}
+
+	cancel()
+
+	log, err = wal.Open[int](dir)
+	if err != nil {
+		t.Fatalf("could not reopen the log: %v", err)
+	}
+
+	defer log.Close()
+
+	ctx, cancel = NewContext[int](context.Background(), WithWAL(log))
+	defer cancel()
+
+	err = common.Run(ctx, Receive(&x))
+	if err != nil || x != 2 {
+		t.Fatalf("expected %d to be replayed, got %d (%v)", 2, x, err)
+	}
+}
@@ -0,0 +1,226 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// Timer is the interface that wraps a single-shot timer created by a Clock.
+type Timer interface {
+	// C returns the channel on which the time is delivered once the timer
+	// fires.
+	//
+	// Returns:
+	//   - <-chan time.Time: The channel of the timer. Never returns nil.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing.
+	//
+	// Returns:
+	//   - bool: True if the call stops the timer, false if the timer has already
+	//     fired or been stopped.
+	Stop() bool
+}
+
+// Clock is the interface that abstracts the passage of time. It allows the
+// code that depends on time to be tested.
+type Clock interface {
+	// Now returns the current time.
+	//
+	// Returns:
+	//   - time.Time: The current time.
+	Now() time.Time
+
+	// NewTimer creates a new Timer that fires after the given duration.
+	//
+	// Parameters:
+	//   - d: The duration after which the timer fires. If it is not positive,
+	//     the timer fires immediately.
+	//
+	// Returns:
+	//   - Timer: The new timer. Never returns nil.
+	NewTimer(d time.Duration) Timer
+}
+
+// RealClock is the Clock that relies on the time package.
+var RealClock Clock
+
+func init() {
+	RealClock = realClock{}
+}
+
+// realClock is the Clock that relies on the time package.
+type realClock struct{}
+
+// Now implements the Clock interface.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer implements the Clock interface.
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{
+		timer: time.NewTimer(d),
+	}
+}
+
+// realTimer is the Timer of the realClock.
+type realTimer struct {
+	// timer is the underlying timer.
+	timer *time.Timer
+}
+
+// C implements the Timer interface.
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Stop implements the Timer interface.
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// ManualClock is a Clock whose time only moves when it is told to. It is meant
+// to be used in tests.
+//
+// An empty ManualClock starts at the zero time and can be created with the
+// `c := new(ManualClock)` constructor.
+type ManualClock struct {
+	// now is the current time of the clock.
+	now time.Time
+
+	// timers are the timers that have not fired yet.
+	timers []*manualTimer
+
+	// mu is the mutex that synchronizes the clock.
+	mu sync.Mutex
+}
+
+// NewManualClock creates a new ManualClock.
+//
+// Parameters:
+//   - now: The initial time of the clock.
+//
+// Returns:
+//   - *ManualClock: The new clock. Never returns nil.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{
+		now: now,
+	}
+}
+
+// Now implements the Clock interface.
+func (c *ManualClock) Now() time.Time {
+	if c == nil {
+		return time.Time{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTimer implements the Clock interface.
+func (c *ManualClock) NewTimer(d time.Duration) Timer {
+	t := &manualTimer{
+		c:     make(chan time.Time, 1),
+		clock: c,
+	}
+
+	if c == nil {
+		t.c <- time.Time{}
+		return t
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t.due = c.now.Add(d)
+
+	if d <= 0 {
+		t.c <- c.now
+	} else {
+		c.timers = append(c.timers, t)
+	}
+
+	return t
+}
+
+// Advance moves the time of the clock forward and fires the timers that are
+// due.
+//
+// Parameters:
+//   - d: The duration to move the clock by. Does nothing if it is not positive.
+func (c *ManualClock) Advance(d time.Duration) {
+	if c == nil || d <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	var top int
+
+	for _, t := range c.timers {
+		if t.due.After(c.now) {
+			c.timers[top] = t
+			top++
+		} else {
+			t.c <- c.now
+		}
+	}
+
+	clear(c.timers[top:])
+	c.timers = c.timers[:top]
+}
+
+// stop removes a timer from the clock.
+//
+// Parameters:
+//   - t: The timer to remove.
+//
+// Returns:
+//   - bool: True if the timer was removed, false if it had already fired or
+//     been stopped.
+func (c *ManualClock) stop(t *manualTimer) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, other := range c.timers {
+		if other == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// manualTimer is the Timer of a ManualClock.
+type manualTimer struct {
+	// c is the channel on which the time is delivered.
+	c chan time.Time
+
+	// due is the time at which the timer fires.
+	due time.Time
+
+	// clock is the clock that created the timer.
+	clock *ManualClock
+}
+
+// C implements the Timer interface.
+func (t *manualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop implements the Timer interface.
+func (t *manualTimer) Stop() bool {
+	return t.clock.stop(t)
+}
@@ -0,0 +1,118 @@
+package common
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+)
+
+// Codec is the interface that encodes values to bytes and decodes them back.
+type Codec[T any] interface {
+	// Encode encodes a value.
+	//
+	// Parameters:
+	//   - value: The value to encode.
+	//
+	// Returns:
+	//   - []byte: The encoded value.
+	//   - error: An error if the value could not be encoded.
+	Encode(value T) ([]byte, error)
+
+	// Decode decodes a value.
+	//
+	// Parameters:
+	//   - data: The encoded value.
+	//
+	// Returns:
+	//   - T: The decoded value.
+	//   - error: An error if the value could not be decoded.
+	Decode(data []byte) (T, error)
+}
+
+// GobCodec is a Codec that relies on the encoding/gob package.
+type GobCodec[T any] struct{}
+
+// Encode implements the Codec interface.
+func (GobCodec[T]) Encode(value T) ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := gob.NewEncoder(&buf).Encode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decode implements the Codec interface.
+func (GobCodec[T]) Decode(data []byte) (T, error) {
+	var value T
+
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+	if err != nil {
+		return *new(T), err
+	}
+
+	return value, nil
+}
+
+// JSONCodec is a Codec that relies on the encoding/json package.
+type JSONCodec[T any] struct{}
+
+// Encode implements the Codec interface.
+func (JSONCodec[T]) Encode(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode implements the Codec interface.
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var value T
+
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return *new(T), err
+	}
+
+	return value, nil
+}
+
+// AppendFrame appends a payload to data, prefixed with its length as an
+// unsigned varint, so that several payloads can be read back one after the
+// other with ReadFrame.
+//
+// Parameters:
+//   - data: The data to append to.
+//   - payload: The payload to append.
+//
+// Returns:
+//   - []byte: The data with the payload appended.
+func AppendFrame(data, payload []byte) []byte {
+	data = binary.AppendUvarint(data, uint64(len(payload)))
+	return append(data, payload...)
+}
+
+// ReadFrame reads a payload appended with AppendFrame.
+//
+// Parameters:
+//   - r: The reader of the data.
+//
+// Returns:
+//   - []byte: The payload. Nil if it could not be read.
+//   - bool: False if the data is truncated or malformed, true otherwise.
+func ReadFrame(r *bytes.Reader) ([]byte, bool) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil || size > uint64(r.Len()) {
+		return nil, false
+	}
+
+	payload := make([]byte, size)
+
+	_, err = io.ReadFull(r, payload)
+	if err != nil {
+		return nil, false
+	}
+
+	return payload, true
+}
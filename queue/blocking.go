@@ -0,0 +1,369 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+	sbj "github.com/PlayerR9/go-safe/subject"
+)
+
+// BlockingQueue is a thread-safe FIFO queue with an optional capacity. Put
+// waits for room when the queue is full and Take waits for an element when it
+// is empty, both until their context is done.
+//
+// An empty, unbounded queue is created by using the `q := new(BlockingQueue[T])`
+// constructor. Use NewBlockingQueue to bound it.
+type BlockingQueue[T any] struct {
+	// values are the elements of the queue, from front to back.
+	values []T
+
+	// capacity is the maximum number of elements. Zero means unbounded.
+	capacity int
+
+	// changed is closed, and replaced, every time an element is added or
+	// removed so that the waiters check the queue again.
+	changed chan struct{}
+
+	// mu is the mutex that synchronizes the queue.
+	mu sync.Mutex
+
+	// observers are the observers of the size.
+	observers sbj.Notifier[int]
+}
+
+// NewBlockingQueue creates a new, empty BlockingQueue.
+//
+// Parameters:
+//   - capacity: The maximum number of elements. If it is not positive, the
+//     queue is unbounded.
+//
+// Returns:
+//   - *BlockingQueue[T]: The new queue. Never returns nil.
+func NewBlockingQueue[T any](capacity int) *BlockingQueue[T] {
+	return &BlockingQueue[T]{
+		capacity: max(capacity, 0),
+	}
+}
+
+// isFull checks whether the queue is full. The caller must hold the lock.
+//
+// Returns:
+//   - bool: True if the queue is full, false otherwise.
+func (q *BlockingQueue[T]) isFull() bool {
+	return q.capacity > 0 && len(q.values) >= q.capacity
+}
+
+// wait returns the channel that is closed on the next change of the queue.
+// The caller must hold the lock.
+//
+// Returns:
+//   - <-chan struct{}: The channel.
+func (q *BlockingQueue[T]) wait() <-chan struct{} {
+	if q.changed == nil {
+		q.changed = make(chan struct{})
+	}
+
+	return q.changed
+}
+
+// signal wakes up the waiters and notifies the observers of the new size. The
+// caller must hold the lock.
+func (q *BlockingQueue[T]) signal() {
+	if q.changed != nil {
+		close(q.changed)
+		q.changed = nil
+	}
+
+	q.observers.Publish(len(q.values))
+}
+
+// tryPut adds an element if the queue is not full.
+//
+// Parameters:
+//   - value: The element to add.
+//
+// Returns:
+//   - <-chan struct{}: Nil if the element was added, the channel to wait on
+//     otherwise.
+func (q *BlockingQueue[T]) tryPut(value T) <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.isFull() {
+		return q.wait()
+	}
+
+	q.values = append(q.values, value)
+	q.signal()
+
+	return nil
+}
+
+// tryTake removes the front element if the queue is not empty.
+//
+// Returns:
+//   - T: The element. The zero value if none was removed.
+//   - <-chan struct{}: Nil if the element was removed, the channel to wait on
+//     otherwise.
+func (q *BlockingQueue[T]) tryTake() (T, <-chan struct{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.values) == 0 {
+		return *new(T), q.wait()
+	}
+
+	value := q.values[0]
+
+	q.values[0] = *new(T)
+	q.values = q.values[1:]
+
+	q.signal()
+
+	return value, nil
+}
+
+// Put adds an element at the back of the queue, waiting for room if the queue
+// is full.
+//
+// Parameters:
+//   - ctx: The context that bounds the wait.
+//   - value: The element to add.
+//
+// Returns:
+//   - error: An error if the element could not be added.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If ctx is nil.
+//   - context.Canceled, context.DeadlineExceeded: If the context is done
+//     before there is room.
+func (q *BlockingQueue[T]) Put(ctx context.Context, value T) error {
+	if q == nil {
+		return common.ErrNilReceiver
+	} else if ctx == nil {
+		return common.NewErrNilParam("ctx")
+	}
+
+	for {
+		wait := q.tryPut(value)
+		if wait == nil {
+			return nil
+		}
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Take removes the front element of the queue, waiting for one if the queue is
+// empty.
+//
+// Parameters:
+//   - ctx: The context that bounds the wait.
+//
+// Returns:
+//   - T: The element. The zero value if an error occurred.
+//   - error: An error if no element could be removed.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If ctx is nil.
+//   - context.Canceled, context.DeadlineExceeded: If the context is done
+//     before an element is available.
+func (q *BlockingQueue[T]) Take(ctx context.Context) (T, error) {
+	if q == nil {
+		return *new(T), common.ErrNilReceiver
+	} else if ctx == nil {
+		return *new(T), common.NewErrNilParam("ctx")
+	}
+
+	for {
+		value, wait := q.tryTake()
+		if wait == nil {
+			return value, nil
+		}
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return *new(T), ctx.Err()
+		}
+	}
+}
+
+// Offer adds an element at the back of the queue, waiting at most the timeout
+// for room if the queue is full.
+//
+// Parameters:
+//   - value: The element to add.
+//   - timeout: The maximum wait. If it is not positive, Offer does not wait.
+//
+// Returns:
+//   - bool: True if the element was added, false otherwise.
+func (q *BlockingQueue[T]) Offer(value T, timeout time.Duration) bool {
+	if q == nil {
+		return false
+	}
+
+	wait := q.tryPut(value)
+	if wait == nil {
+		return true
+	} else if timeout <= 0 {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return q.Put(ctx, value) == nil
+}
+
+// Poll removes the front element of the queue, waiting at most the timeout for
+// one if the queue is empty.
+//
+// Parameters:
+//   - timeout: The maximum wait. If it is not positive, Poll does not wait.
+//
+// Returns:
+//   - T: The element. The zero value if none was removed.
+//   - bool: True if an element was removed, false otherwise.
+func (q *BlockingQueue[T]) Poll(timeout time.Duration) (T, bool) {
+	if q == nil {
+		return *new(T), false
+	}
+
+	value, wait := q.tryTake()
+	if wait == nil {
+		return value, true
+	} else if timeout <= 0 {
+		return *new(T), false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	value, err := q.Take(ctx)
+	return value, err == nil
+}
+
+// DrainTo removes the elements of the queue, from front to back, and appends
+// them to dst without waiting.
+//
+// Parameters:
+//   - dst: The slice to append the elements to.
+//   - n: The maximum number of elements to remove. If it is not positive, every
+//     element is removed.
+//
+// Returns:
+//   - []T: The slice with the elements appended.
+func (q *BlockingQueue[T]) DrainTo(dst []T, n int) []T {
+	if q == nil {
+		return dst
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if n <= 0 || n > len(q.values) {
+		n = len(q.values)
+	}
+
+	if n == 0 {
+		return dst
+	}
+
+	dst = append(dst, q.values[:n]...)
+
+	clear(q.values[:n])
+	q.values = q.values[n:]
+
+	q.signal()
+
+	return dst
+}
+
+// Size returns the number of elements in the queue.
+//
+// Returns:
+//   - int: The size of the queue.
+func (q *BlockingQueue[T]) Size() int {
+	if q == nil {
+		return 0
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.values)
+}
+
+// Capacity returns the maximum number of elements of the queue.
+//
+// Returns:
+//   - int: The capacity. Zero if the queue is unbounded.
+func (q *BlockingQueue[T]) Capacity() int {
+	if q == nil {
+		return 0
+	}
+
+	return q.capacity
+}
+
+// IsEmpty checks whether the queue is empty.
+//
+// Returns:
+//   - bool: True if the queue is empty, false otherwise.
+func (q *BlockingQueue[T]) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// ObserveSizeSync adds a synchronous observer to the size of the queue. It is
+// called on every change, in order, while the queue is locked; so it must be
+// cheap and must not call the methods of the queue. Does nothing if the
+// function is nil.
+//
+// Parameters:
+//   - fn: The function to be called when the size changes.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+func (q *BlockingQueue[T]) ObserveSizeSync(fn sbj.Action[int]) error {
+	if fn == nil {
+		return nil
+	} else if q == nil {
+		return common.ErrNilReceiver
+	}
+
+	_ = q.observers.Observe(fn)
+
+	return nil
+}
+
+// ObserveSize adds an asynchronous observer to the size of the queue. It is
+// called from its own goroutine with the latest size, so it may call the
+// methods of the queue; the changes that happen while it runs are coalesced,
+// so it may skip intermediate sizes but always sees the last one. Use
+// ObserveSizeSync to see every change. Does nothing if the function is nil.
+//
+// Parameters:
+//   - fn: The function to be called when the size changes.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+func (q *BlockingQueue[T]) ObserveSize(fn sbj.Action[int]) error {
+	if fn == nil {
+		return nil
+	} else if q == nil {
+		return common.ErrNilReceiver
+	}
+
+	_ = q.observers.ObserveCoalesced(fn)
+
+	return nil
+}
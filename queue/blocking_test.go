@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBlockingQueue(t *testing.T) {
+	const (
+		MaxCount int = 100
+	)
+
+	q := NewBlockingQueue[int](2)
+
+	go func() {
+		for i := 0; i < MaxCount; i++ {
+			err := q.Put(context.Background(), i)
+			if err != nil {
+				t.Errorf("could not put %d: %v", i, err)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < MaxCount; i++ {
+		x, err := q.Take(context.Background())
+		if err != nil {
+			t.Fatalf("could not take %d: %v", i, err)
+		} else if x != i {
+			t.Fatalf("expected %d, got %d", i, x)
+		}
+
+		if q.Size() > q.Capacity() {
+			t.Fatalf("expected at most %d elements, got %d", q.Capacity(), q.Size())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := q.Take(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	if !q.Offer(1, 0) || !q.Offer(2, 0) {
+		t.Fatalf("expected room for two elements")
+	} else if q.Offer(3, 10*time.Millisecond) {
+		t.Fatalf("expected the queue to be full")
+	}
+
+	x, ok := q.Poll(0)
+	if !ok || x != 1 {
+		t.Fatalf("expected %d, got %d", 1, x)
+	}
+
+	values := q.DrainTo(nil, 0)
+	if len(values) != 1 || values[0] != 2 {
+		t.Fatalf("expected [2], got %v", values)
+	}
+
+	_, ok = q.Poll(10 * time.Millisecond)
+	if ok {
+		t.Fatalf("expected the queue to be empty")
+	}
+}
@@ -0,0 +1,472 @@
+package queue
+
+import (
+	"iter"
+	"sync"
+
+	"github.com/PlayerR9/go-safe/common"
+	sbj "github.com/PlayerR9/go-safe/subject"
+)
+
+// Deque is a generic type that represents a thread-safe double-ended queue
+// without a limited capacity, implemented using a growable ring buffer.
+//
+// An empty deque is created by using the `d := new(Deque[T])` constructor.
+type Deque[T any] struct {
+	// values is the ring buffer of the elements.
+	values []T
+
+	// head is the index of the front element in values.
+	head int
+
+	// size is the number of elements in the deque.
+	size int
+
+	// mu is the mutex that synchronizes the deque.
+	mu sync.RWMutex
+
+	// observers are the observers of the size.
+	observers sbj.Notifier[int]
+}
+
+// index returns the index in values of the i-th element from the front. The
+// caller must hold the lock.
+//
+// Parameters:
+//   - i: The position of the element. It must be in [0, size).
+//
+// Returns:
+//   - int: The index in values.
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) % len(d.values)
+}
+
+// grow makes room for one more element. The caller must hold the lock.
+func (d *Deque[T]) grow() {
+	if d.size < len(d.values) {
+		return
+	}
+
+	values := make([]T, max(2*len(d.values), 4))
+
+	for i := 0; i < d.size; i++ {
+		values[i] = d.values[d.index(i)]
+	}
+
+	d.values = values
+	d.head = 0
+}
+
+// PushFront adds an element at the front of the deque.
+//
+// Parameters:
+//   - value: The element to add.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+func (d *Deque[T]) PushFront(value T) error {
+	if d == nil {
+		return common.ErrNilReceiver
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.grow()
+
+	d.head = (d.head - 1 + len(d.values)) % len(d.values)
+	d.values[d.head] = value
+	d.size++
+
+	d.observers.Publish(d.size)
+
+	return nil
+}
+
+// PushBack adds an element at the back of the deque.
+//
+// Parameters:
+//   - value: The element to add.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+func (d *Deque[T]) PushBack(value T) error {
+	if d == nil {
+		return common.ErrNilReceiver
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.grow()
+
+	d.values[d.index(d.size)] = value
+	d.size++
+
+	d.observers.Publish(d.size)
+
+	return nil
+}
+
+// PopFront removes and returns the front element of the deque.
+//
+// Returns:
+//   - T: The front element.
+//   - error: An error if the deque is empty.
+//
+// Errors:
+//   - ErrEmptyQueue: If the deque is empty.
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (d *Deque[T]) PopFront() (T, error) {
+	if d == nil {
+		return *new(T), common.ErrNilReceiver
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.size == 0 {
+		return *new(T), ErrEmptyQueue
+	}
+
+	value := d.values[d.head]
+
+	d.values[d.head] = *new(T)
+	d.head = d.index(1)
+	d.size--
+
+	d.observers.Publish(d.size)
+
+	return value, nil
+}
+
+// PopBack removes and returns the back element of the deque.
+//
+// Returns:
+//   - T: The back element.
+//   - error: An error if the deque is empty.
+//
+// Errors:
+//   - ErrEmptyQueue: If the deque is empty.
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (d *Deque[T]) PopBack() (T, error) {
+	if d == nil {
+		return *new(T), common.ErrNilReceiver
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.size == 0 {
+		return *new(T), ErrEmptyQueue
+	}
+
+	idx := d.index(d.size - 1)
+	value := d.values[idx]
+
+	d.values[idx] = *new(T)
+	d.size--
+
+	d.observers.Publish(d.size)
+
+	return value, nil
+}
+
+// PeekFront returns the front element of the deque without removing it.
+//
+// Returns:
+//   - T: The front element.
+//   - error: An error if the deque is empty.
+//
+// Errors:
+//   - ErrEmptyQueue: If the deque is empty.
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (d *Deque[T]) PeekFront() (T, error) {
+	return d.At(0)
+}
+
+// PeekBack returns the back element of the deque without removing it.
+//
+// Returns:
+//   - T: The back element.
+//   - error: An error if the deque is empty.
+//
+// Errors:
+//   - ErrEmptyQueue: If the deque is empty.
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (d *Deque[T]) PeekBack() (T, error) {
+	if d == nil {
+		return *new(T), common.ErrNilReceiver
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.size == 0 {
+		return *new(T), ErrEmptyQueue
+	}
+
+	return d.values[d.index(d.size-1)], nil
+}
+
+// At returns the element at a position, counted from the front.
+//
+// Parameters:
+//   - idx: The position of the element.
+//
+// Returns:
+//   - T: The element.
+//   - error: An error if there is no element at the position.
+//
+// Errors:
+//   - ErrEmptyQueue: If the deque is empty.
+//   - common.ErrBadParam: If idx is out of range.
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (d *Deque[T]) At(idx int) (T, error) {
+	if d == nil {
+		return *new(T), common.ErrNilReceiver
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.size == 0 {
+		return *new(T), ErrEmptyQueue
+	} else if idx < 0 || idx >= d.size {
+		return *new(T), common.NewErrBadParam("idx", "is out of range")
+	}
+
+	return d.values[d.index(idx)], nil
+}
+
+// Set replaces the element at a position, counted from the front.
+//
+// Parameters:
+//   - idx: The position of the element.
+//   - value: The new element.
+//
+// Returns:
+//   - error: An error if there is no element at the position.
+//
+// Errors:
+//   - common.ErrBadParam: If idx is out of range.
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (d *Deque[T]) Set(idx int, value T) error {
+	if d == nil {
+		return common.ErrNilReceiver
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if idx < 0 || idx >= d.size {
+		return common.NewErrBadParam("idx", "is out of range")
+	}
+
+	d.values[d.index(idx)] = value
+
+	return nil
+}
+
+// Rotate rotates the deque: with a positive n, the n front elements move to
+// the back, and with a negative n, the -n back elements move to the front. Does
+// nothing if the deque has fewer than two elements. It never allocates.
+//
+// Parameters:
+//   - n: The number of steps.
+func (d *Deque[T]) Rotate(n int) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.size < 2 {
+		return
+	}
+
+	n %= d.size
+	if n < 0 {
+		n += d.size
+	}
+
+	if n == 0 {
+		return
+	}
+
+	if d.size == len(d.values) {
+		// The ring is full, so moving the head moves the elements.
+		d.head = d.index(n)
+		return
+	}
+
+	// Move the elements one by one through the free slots, in the direction
+	// that needs the fewest moves.
+	if n <= d.size-n {
+		for range n {
+			tail := d.index(d.size)
+
+			d.values[tail] = d.values[d.head]
+			d.values[d.head] = *new(T)
+			d.head = d.index(1)
+		}
+
+		return
+	}
+
+	for range d.size - n {
+		last := d.index(d.size - 1)
+
+		d.head = (d.head - 1 + len(d.values)) % len(d.values)
+		d.values[d.head] = d.values[last]
+		d.values[last] = *new(T)
+	}
+}
+
+// Size returns the number of elements in the deque.
+//
+// Returns:
+//   - int: The size of the deque.
+func (d *Deque[T]) Size() int {
+	if d == nil {
+		return 0
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.size
+}
+
+// IsEmpty checks whether the deque is empty.
+//
+// Returns:
+//   - bool: True if the deque is empty, false otherwise.
+func (d *Deque[T]) IsEmpty() bool {
+	return d.Size() == 0
+}
+
+// Reset removes all elements from the deque.
+func (d *Deque[T]) Reset() {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.size == 0 {
+		return
+	}
+
+	d.values = nil
+	d.head = 0
+	d.size = 0
+
+	d.observers.Publish(0)
+}
+
+// Slice returns a copy of the elements of the deque, from front to back.
+//
+// Returns:
+//   - []T: A copy of the elements. Nil if the deque is empty.
+func (d *Deque[T]) Slice() []T {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.size == 0 {
+		return nil
+	}
+
+	slice := make([]T, d.size)
+
+	for i := range slice {
+		slice[i] = d.values[d.index(i)]
+	}
+
+	return slice
+}
+
+// All returns an iterator over the positions and elements of the deque, from
+// front to back. It iterates over a snapshot taken when the iteration starts,
+// so the deque can be modified while iterating.
+//
+// Returns:
+//   - iter.Seq2[int, T]: The iterator. Never returns nil.
+func (d *Deque[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, value := range d.Slice() {
+			if !yield(i, value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the positions and elements of the deque,
+// from back to front. It iterates over a snapshot taken when the iteration
+// starts, so the deque can be modified while iterating.
+//
+// Returns:
+//   - iter.Seq2[int, T]: The iterator. Never returns nil.
+func (d *Deque[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		slice := d.Slice()
+
+		for i := len(slice) - 1; i >= 0; i-- {
+			if !yield(i, slice[i]) {
+				return
+			}
+		}
+	}
+}
+
+// ObserveSizeSync adds a synchronous observer to the size of the deque. It is
+// called on every change, in order, while the deque is locked; so it must be
+// cheap and must not call the methods of the deque. Does nothing if the
+// function is nil.
+//
+// Parameters:
+//   - fn: The function to be called when the size changes.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+func (d *Deque[T]) ObserveSizeSync(fn sbj.Action[int]) error {
+	if fn == nil {
+		return nil
+	} else if d == nil {
+		return common.ErrNilReceiver
+	}
+
+	_ = d.observers.Observe(fn)
+
+	return nil
+}
+
+// ObserveSize adds an asynchronous observer to the size of the deque. It is
+// called from its own goroutine with the latest size, so it may call the
+// methods of the deque; the changes that happen while it runs are coalesced,
+// so it may skip intermediate sizes but always sees the last one. Use
+// ObserveSizeSync to see every change. Does nothing if the function is nil.
+//
+// Parameters:
+//   - fn: The function to be called when the size changes.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+func (d *Deque[T]) ObserveSize(fn sbj.Action[int]) error {
+	if fn == nil {
+		return nil
+	} else if d == nil {
+		return common.ErrNilReceiver
+	}
+
+	_ = d.observers.ObserveCoalesced(fn)
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package queue
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDeque(t *testing.T) {
+	d := new(Deque[int])
+
+	_, err := d.PopBack()
+	if err != ErrEmptyQueue {
+		t.Fatalf("expected %v, got %v", ErrEmptyQueue, err)
+	}
+
+	for i := 0; i < 5; i++ {
+		_ = d.PushBack(i)
+	}
+
+	_ = d.PushFront(-1)
+
+	expected := []int{-1, 0, 1, 2, 3, 4}
+	if !slices.Equal(d.Slice(), expected) {
+		t.Fatalf("expected %v, got %v", expected, d.Slice())
+	}
+
+	x, err := d.At(3)
+	if err != nil || x != 2 {
+		t.Fatalf("expected %d, got %d", 2, x)
+	}
+
+	_, err = d.At(6)
+	if err == nil {
+		t.Fatalf("expected an out of range error")
+	}
+
+	d.Rotate(2)
+
+	expected = []int{1, 2, 3, 4, -1, 0}
+	if !slices.Equal(d.Slice(), expected) {
+		t.Fatalf("expected %v, got %v", expected, d.Slice())
+	}
+
+	d.Rotate(-3)
+
+	expected = []int{4, -1, 0, 1, 2, 3}
+	if !slices.Equal(d.Slice(), expected) {
+		t.Fatalf("expected %v, got %v", expected, d.Slice())
+	}
+
+	var backward []int
+
+	for _, value := range d.Backward() {
+		backward = append(backward, value)
+	}
+
+	slices.Reverse(expected)
+
+	if !slices.Equal(backward, expected) {
+		t.Fatalf("expected %v, got %v", expected, backward)
+	}
+
+	front, _ := d.PopFront()
+	back, _ := d.PopBack()
+
+	if front != 4 || back != 3 {
+		t.Fatalf("expected %d and %d, got %d and %d", 4, 3, front, back)
+	} else if d.Size() != 4 {
+		t.Fatalf("expected %d elements, got %d", 4, d.Size())
+	}
+}
+
+func TestDequeRotate(t *testing.T) {
+	d := new(Deque[int])
+
+	var expected []int
+
+	for i := range 20 {
+		_ = d.PushBack(i)
+		expected = append(expected, i)
+
+		for _, n := range []int{1, -2, i, 3 * i, -i - 1} {
+			d.Rotate(n)
+
+			k := n % len(expected)
+			if k < 0 {
+				k += len(expected)
+			}
+
+			expected = slices.Concat(expected[k:], expected[:k])
+
+			if !slices.Equal(d.Slice(), expected) {
+				t.Fatalf("after Rotate(%d): expected %v, got %v", n, expected, d.Slice())
+			}
+		}
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		d.Rotate(3)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected Rotate not to allocate, got %v allocations", allocs)
+	}
+}
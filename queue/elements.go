@@ -0,0 +1,261 @@
+package queue
+
+import (
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// removeWhere removes the elements for which the predicate returns the given
+// result. The queue must be locked and the size observers are notified once if
+// any element was removed.
+//
+// Parameters:
+//   - pred: The predicate. Assumed to be non-nil.
+//   - result: The result of the predicate for the elements to remove.
+//
+// Returns:
+//   - int: The number of removed elements.
+func (queue *Queue[T]) removeWhere(pred func(T) bool, result bool) int {
+	var removed int
+	var prev *queue_node[T]
+
+	for node := queue.front; node != nil; node = node.next {
+		if pred(node.value) != result {
+			prev = node
+			continue
+		}
+
+		if prev == nil {
+			queue.front = node.next
+		} else {
+			prev.next = node.next
+		}
+
+		if queue.back == node {
+			queue.back = prev
+		}
+
+		removed++
+	}
+
+	if removed == 0 {
+		return 0
+	}
+
+	queue.size -= removed
+	queue.observers.Publish(queue.size)
+
+	return removed
+}
+
+// RemoveIf removes every element of the queue for which the predicate returns
+// true. The size observers are notified once.
+//
+// Parameters:
+//   - pred: The predicate. It is called while the queue is locked, so it must
+//     not call the methods of the queue.
+//
+// Returns:
+//   - int: The number of removed elements. 0 if the receiver or pred is nil.
+func (queue *Queue[T]) RemoveIf(pred func(T) bool) int {
+	if queue == nil || pred == nil {
+		return 0
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	return queue.removeWhere(pred, true)
+}
+
+// Retain keeps only the elements of the queue for which the predicate returns
+// true. The size observers are notified once.
+//
+// Parameters:
+//   - pred: The predicate. It is called while the queue is locked, so it must
+//     not call the methods of the queue.
+//
+// Returns:
+//   - int: The number of removed elements. 0 if the receiver or pred is nil.
+func (queue *Queue[T]) Retain(pred func(T) bool) int {
+	if queue == nil || pred == nil {
+		return 0
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	return queue.removeWhere(pred, false)
+}
+
+// Find returns the first element of the queue for which the predicate returns
+// true.
+//
+// Parameters:
+//   - pred: The predicate. It is called while the queue is locked, so it must
+//     not call the methods of the queue that modify it.
+//
+// Returns:
+//   - T: The element. The zero value if none was found.
+//   - bool: True if an element was found, false otherwise.
+func (queue *Queue[T]) Find(pred func(T) bool) (T, bool) {
+	if queue == nil || pred == nil {
+		return *new(T), false
+	}
+
+	queue.mu.RLock()
+	defer queue.mu.RUnlock()
+
+	for node := queue.front; node != nil; node = node.next {
+		if pred(node.value) {
+			return node.value, true
+		}
+	}
+
+	return *new(T), false
+}
+
+// Contains checks whether the queue contains an element.
+//
+// Parameters:
+//   - eq: The function that returns true for the element looked for. It is
+//     called while the queue is locked, so it must not call the methods of the
+//     queue that modify it.
+//
+// Returns:
+//   - bool: True if the queue contains the element, false otherwise.
+func (queue *Queue[T]) Contains(eq func(T) bool) bool {
+	_, ok := queue.Find(eq)
+	return ok
+}
+
+// InsertAt inserts an element at a position of the queue. The element that was
+// at that position, and the ones after it, are shifted towards the back.
+//
+// Parameters:
+//   - idx: The position, between 0 (the front) and the size of the queue (the
+//     back), inclusive.
+//   - value: The element to insert.
+//
+// Returns:
+//   - error: An error if the element could not be inserted.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If idx is out of range.
+func (queue *Queue[T]) InsertAt(idx int, value T) error {
+	if queue == nil {
+		return common.ErrNilReceiver
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	if idx < 0 || idx > queue.size {
+		return common.NewErrBadParam("idx", "must be between 0 and the size of the queue")
+	}
+
+	node := &queue_node[T]{
+		value: value,
+	}
+
+	switch {
+	case idx == 0:
+		node.next = queue.front
+		queue.front = node
+
+		if queue.back == nil {
+			queue.back = node
+		}
+	case idx == queue.size:
+		queue.back.next = node
+		queue.back = node
+	default:
+		prev := queue.front
+
+		for i := 1; i < idx; i++ {
+			prev = prev.next
+		}
+
+		node.next = prev.next
+		prev.next = node
+	}
+
+	queue.size++
+	queue.observers.Publish(queue.size)
+
+	return nil
+}
+
+// DequeueIf removes and returns the first element of the queue only if the
+// predicate returns true for it.
+//
+// Parameters:
+//   - pred: The predicate. It is called while the queue is locked, so it must
+//     not call the methods of the queue.
+//
+// Returns:
+//   - T: The first element. The zero value if it was not removed.
+//   - bool: True if the element was removed, false if the queue is empty or
+//     the predicate returned false.
+func (queue *Queue[T]) DequeueIf(pred func(T) bool) (T, bool) {
+	if queue == nil || pred == nil {
+		return *new(T), false
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	if queue.front == nil || !pred(queue.front.value) {
+		return *new(T), false
+	}
+
+	value := queue.front.value
+
+	queue.front = queue.front.next
+	if queue.front == nil {
+		queue.back = nil
+	}
+
+	queue.size--
+	queue.observers.Publish(queue.size)
+
+	return value, true
+}
+
+// DequeueN removes and returns up to n elements from the front of the queue.
+// The size observers are notified once.
+//
+// Parameters:
+//   - n: The maximum number of elements to remove.
+//
+// Returns:
+//   - []T: The removed elements, in order. Nil if none was removed.
+func (queue *Queue[T]) DequeueN(n int) []T {
+	if queue == nil || n <= 0 {
+		return nil
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	n = min(n, queue.size)
+	if n == 0 {
+		return nil
+	}
+
+	values := make([]T, 0, n)
+
+	for range n {
+		values = append(values, queue.front.value)
+		queue.front = queue.front.next
+	}
+
+	if queue.front == nil {
+		queue.back = nil
+	}
+
+	queue.size -= n
+	queue.observers.Publish(queue.size)
+
+	return values
+}
@@ -0,0 +1,96 @@
+package queue
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestElements(t *testing.T) {
+	q := new(Queue[int])
+
+	var sizes []int
+
+	_ = q.ObserveSizeSync(func(size int) error {
+		sizes = append(sizes, size)
+		return nil
+	})
+
+	_ = q.EnqueueMany([]int{1, 2, 3, 4, 5, 6})
+
+	sizes = nil
+
+	isEven := func(v int) bool { return v%2 == 0 }
+
+	n := q.RemoveIf(isEven)
+	if n != 3 {
+		t.Fatalf("expected to remove 3 elements, got %d", n)
+	}
+
+	got := q.Slice()
+	if !slices.Equal(got, []int{1, 3, 5}) {
+		t.Fatalf("expected [1 3 5], got %v", got)
+	}
+
+	if !q.Contains(func(v int) bool { return v == 3 }) {
+		t.Fatalf("expected the queue to contain 3")
+	}
+
+	v, ok := q.Find(func(v int) bool { return v > 1 })
+	if !ok || v != 3 {
+		t.Fatalf("expected to find 3, got %d (%t)", v, ok)
+	}
+
+	for _, tc := range [][2]int{{0, 0}, {4, 9}, {2, 2}} {
+		err := q.InsertAt(tc[0], tc[1])
+		if err != nil {
+			t.Fatalf("could not insert at %d: %v", tc[0], err)
+		}
+	}
+
+	got = q.Slice()
+	if !slices.Equal(got, []int{0, 1, 2, 3, 5, 9}) {
+		t.Fatalf("expected [0 1 2 3 5 9], got %v", got)
+	}
+
+	err := q.InsertAt(7, 7)
+	if err == nil {
+		t.Fatalf("expected an error when inserting out of range")
+	}
+
+	n = q.Retain(func(v int) bool { return v < 5 })
+	if n != 2 {
+		t.Fatalf("expected to remove 2 elements, got %d", n)
+	}
+
+	_, ok = q.DequeueIf(func(v int) bool { return v == 1 })
+	if ok {
+		t.Fatalf("expected DequeueIf not to remove 0")
+	}
+
+	v, ok = q.DequeueIf(isEven)
+	if !ok || v != 0 {
+		t.Fatalf("expected DequeueIf to remove 0, got %d (%t)", v, ok)
+	}
+
+	got = q.DequeueN(10)
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+
+	if !q.IsEmpty() {
+		t.Fatalf("expected the queue to be empty")
+	}
+
+	_ = q.Enqueue(8)
+
+	got = q.Slice()
+	if !slices.Equal(got, []int{8}) {
+		t.Fatalf("expected [8], got %v", got)
+	}
+
+	// One notification per operation.
+	expected := []int{3, 4, 5, 6, 4, 3, 0, 1}
+	if !slices.Equal(sizes, expected) {
+		t.Fatalf("expected %v, got %v", expected, sizes)
+	}
+}
@@ -8,8 +8,43 @@ var (
 	// Format:
 	//   "queue is empty"
 	ErrEmptyQueue error
+
+	// ErrBadSnapshot occurs when a snapshot is malformed.
+	//
+	// Format:
+	//   "snapshot is malformed"
+	ErrBadSnapshot error
+
+	// ErrStaleHandle occurs when a handle refers to an element that is no
+	// longer in its queue.
+	//
+	// Format:
+	//   "element is no longer in the queue"
+	ErrStaleHandle error
+
+	// ErrNoWorkPool occurs when Spawn is not run with the context of a task of
+	// a WorkPool.
+	//
+	// Format:
+	//   "not running in a work pool"
+	ErrNoWorkPool error
+
+	// ErrPoolClosed occurs when a task is submitted to a WorkPool that is
+	// closed.
+	//
+	// Format:
+	//   "work pool is closed"
+	ErrPoolClosed error
 )
 
 func init() {
 	ErrEmptyQueue = errors.New("queue is empty")
+
+	ErrBadSnapshot = errors.New("snapshot is malformed")
+
+	ErrStaleHandle = errors.New("element is no longer in the queue")
+
+	ErrNoWorkPool = errors.New("not running in a work pool")
+
+	ErrPoolClosed = errors.New("work pool is closed")
 }
@@ -0,0 +1,103 @@
+package queue
+
+import (
+	"iter"
+)
+
+// liveBatch is the number of elements Live reads each time it locks the queue.
+const liveBatch int = 64
+
+// All returns an iterator over the elements of the queue, from front to back.
+// It iterates over a consistent snapshot taken when the iteration starts, so
+// the queue can be modified while iterating.
+//
+// Returns:
+//   - iter.Seq[T]: The iterator. Never returns nil.
+func (queue *Queue[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, value := range queue.Slice() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the elements of the queue, from back to
+// front. It iterates over a consistent snapshot taken when the iteration
+// starts, so the queue can be modified while iterating.
+//
+// Returns:
+//   - iter.Seq[T]: The iterator. Never returns nil.
+func (queue *Queue[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		slice := queue.Slice()
+
+		for i := len(slice) - 1; i >= 0; i-- {
+			if !yield(slice[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Live returns a weakly consistent iterator over the elements of the queue,
+// from front to back. It does not copy the queue and only locks it briefly
+// every few elements, so the queue can be modified while iterating.
+//
+// The elements that are in the queue when the iteration starts and are not
+// removed while iterating are yielded exactly once, in order. The elements
+// added or removed while iterating may or may not be yielded.
+//
+// Returns:
+//   - iter.Seq[T]: The iterator. Never returns nil.
+func (queue *Queue[T]) Live() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if queue == nil {
+			return
+		}
+
+		queue.mu.RLock()
+		node := queue.front
+		queue.mu.RUnlock()
+
+		batch := make([]T, 0, liveBatch)
+
+		for node != nil {
+			queue.mu.RLock()
+
+			for ; node != nil && len(batch) < liveBatch; node = node.next {
+				batch = append(batch, node.value)
+			}
+
+			queue.mu.RUnlock()
+
+			for _, value := range batch {
+				if !yield(value) {
+					return
+				}
+			}
+
+			clear(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// DequeueAll returns an iterator that dequeues the elements of the queue, from
+// front to back, until it is empty. The elements enqueued while iterating are
+// dequeued too. Breaking out of the loop leaves the remaining elements in the
+// queue.
+//
+// Returns:
+//   - iter.Seq[T]: The iterator. Never returns nil.
+func (queue *Queue[T]) DequeueAll() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			value, err := queue.Dequeue()
+			if err != nil || !yield(value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package queue
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestIter(t *testing.T) {
+	q := new(Queue[int])
+
+	_ = q.EnqueueMany([]int{1, 2, 3, 4})
+
+	var got []int
+
+	for v := range q.All() {
+		got = append(got, v)
+
+		_ = q.Enqueue(v * 10)
+	}
+
+	if !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Fatalf("expected All to iterate over a snapshot, got %v", got)
+	}
+
+	got = slices.Collect(q.Backward())
+
+	if !slices.Equal(got, []int{40, 30, 20, 10, 4, 3, 2, 1}) {
+		t.Fatalf("expected the elements in reverse, got %v", got)
+	}
+
+	got = nil
+
+	for v := range q.DequeueAll() {
+		got = append(got, v)
+
+		if v == 10 {
+			break
+		}
+	}
+
+	if !slices.Equal(got, []int{1, 2, 3, 4, 10}) {
+		t.Fatalf("expected [1 2 3 4 10], got %v", got)
+	}
+
+	got = slices.Collect(q.DequeueAll())
+	if !slices.Equal(got, []int{20, 30, 40}) {
+		t.Fatalf("expected [20 30 40], got %v", got)
+	}
+
+	if !q.IsEmpty() {
+		t.Fatalf("expected the queue to be empty")
+	}
+}
+
+func TestLive(t *testing.T) {
+	const N int = 1000
+
+	q := new(Queue[int])
+
+	for i := range N {
+		_ = q.Enqueue(i)
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for i := range N {
+			_ = q.Enqueue(N + i)
+			_ = q.RemoveIf(func(v int) bool { return v >= N && v%2 == 1 })
+		}
+	}()
+
+	var got []int
+
+	for v := range q.Live() {
+		if v < N {
+			got = append(got, v)
+		}
+	}
+
+	wg.Wait()
+
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("expected every initial element in order, got %d at %d", v, i)
+		}
+	}
+
+	if len(got) != N {
+		t.Fatalf("expected %d initial elements, got %d", N, len(got))
+	}
+}
@@ -0,0 +1,173 @@
+package queue
+
+import (
+	"sync/atomic"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// lockFreeNode represents a node in a lock-free queue.
+type lockFreeNode[T any] struct {
+	// value is the value stored in the node.
+	value T
+
+	// next is a pointer to the next node in the queue.
+	next atomic.Pointer[lockFreeNode[T]]
+}
+
+// LockFreeQueue is an unbounded, multi-producer multi-consumer FIFO queue that
+// never takes a lock. It is a Michael–Scott linked queue: producers and
+// consumers only synchronize through compare-and-swap operations on the back
+// and front of the queue, so they never block each other.
+//
+// Unlike Queue, it has no size observers. The value most recently dequeued is
+// kept alive until the next Dequeue, as its node becomes the sentinel of the
+// queue.
+//
+// An empty queue is created by using the `q := new(LockFreeQueue[T])`
+// constructor.
+type LockFreeQueue[T any] struct {
+	// head is the sentinel node; the front value is in its successor.
+	head atomic.Pointer[lockFreeNode[T]]
+
+	// tail is the last node, or lags one node behind it.
+	tail atomic.Pointer[lockFreeNode[T]]
+
+	// size is the number of values in the queue.
+	size atomic.Int64
+}
+
+// sentinel returns the sentinel node, creating it on first use.
+//
+// Returns:
+//   - *lockFreeNode[T]: The sentinel node. Never returns nil.
+func (q *LockFreeQueue[T]) sentinel() *lockFreeNode[T] {
+	head := q.head.Load()
+	if head == nil {
+		_ = q.head.CompareAndSwap(nil, new(lockFreeNode[T]))
+		head = q.head.Load()
+	}
+
+	// The goroutine that created the sentinel may not have published the tail
+	// yet.
+	_ = q.tail.CompareAndSwap(nil, head)
+
+	return head
+}
+
+// Enqueue implements the Queuer interface.
+func (q *LockFreeQueue[T]) Enqueue(value T) error {
+	if q == nil {
+		return common.ErrNilReceiver
+	}
+
+	_ = q.sentinel()
+
+	node := &lockFreeNode[T]{
+		value: value,
+	}
+
+	for {
+		tail := q.tail.Load()
+		next := tail.next.Load()
+
+		if tail != q.tail.Load() {
+			continue
+		}
+
+		if next != nil {
+			// The tail is lagging behind; help move it forward.
+			_ = q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+
+		if tail.next.CompareAndSwap(nil, node) {
+			_ = q.tail.CompareAndSwap(tail, node)
+			break
+		}
+	}
+
+	q.size.Add(1)
+
+	return nil
+}
+
+// Dequeue implements the Queuer interface.
+//
+// Errors:
+//   - ErrEmptyQueue: If the queue is empty.
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (q *LockFreeQueue[T]) Dequeue() (T, error) {
+	if q == nil {
+		return *new(T), common.ErrNilReceiver
+	}
+
+	_ = q.sentinel()
+
+	for {
+		head := q.head.Load()
+		tail := q.tail.Load()
+		next := head.next.Load()
+
+		if head != q.head.Load() {
+			continue
+		}
+
+		if next == nil {
+			return *new(T), ErrEmptyQueue
+		}
+
+		if head == tail {
+			// The tail is lagging behind; help move it forward.
+			_ = q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+
+		value := next.value
+
+		if q.head.CompareAndSwap(head, next) {
+			q.size.Add(-1)
+
+			return value, nil
+		}
+	}
+}
+
+// Peek implements the Queuer interface.
+//
+// Errors:
+//   - ErrEmptyQueue: If the queue is empty.
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (q *LockFreeQueue[T]) Peek() (T, error) {
+	if q == nil {
+		return *new(T), common.ErrNilReceiver
+	}
+
+	next := q.sentinel().next.Load()
+	if next == nil {
+		return *new(T), ErrEmptyQueue
+	}
+
+	return next.value, nil
+}
+
+// IsEmpty implements the Queuer interface.
+func (q *LockFreeQueue[T]) IsEmpty() bool {
+	if q == nil {
+		return true
+	}
+
+	return q.sentinel().next.Load() == nil
+}
+
+// Size implements the Queuer interface.
+//
+// While values are being enqueued or dequeued concurrently, the size is only
+// an approximation.
+func (q *LockFreeQueue[T]) Size() int {
+	if q == nil {
+		return 0
+	}
+
+	return max(int(q.size.Load()), 0)
+}
@@ -0,0 +1,165 @@
+package queue
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// stress enqueues values from several producers while several consumers
+// dequeue them, and checks that every value is dequeued exactly once and that
+// the values of each producer are dequeued in order.
+func stress(t *testing.T, q Queuer[[2]int]) {
+	const (
+		Producers int = 8
+		Consumers int = 8
+		PerProd   int = 2000
+	)
+
+	var wg sync.WaitGroup
+
+	wg.Add(Producers)
+
+	for p := 0; p < Producers; p++ {
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < PerProd; i++ {
+				err := q.Enqueue([2]int{p, i})
+				if err != nil {
+					t.Errorf("could not enqueue: %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	results := make([][][2]int, Consumers)
+	done := make(chan struct{})
+
+	var cwg sync.WaitGroup
+
+	cwg.Add(Consumers)
+
+	for c := 0; c < Consumers; c++ {
+		go func() {
+			defer cwg.Done()
+
+			for {
+				v, err := q.Dequeue()
+				if err == nil {
+					results[c] = append(results[c], v)
+					continue
+				}
+
+				select {
+				case <-done:
+					// Drain what was enqueued before the producers finished.
+					for {
+						v, err := q.Dequeue()
+						if err != nil {
+							return
+						}
+
+						results[c] = append(results[c], v)
+					}
+				default:
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+	cwg.Wait()
+
+	seen := make(map[[2]int]bool)
+
+	for _, result := range results {
+		last := make(map[int]int)
+
+		for _, v := range result {
+			if seen[v] {
+				t.Fatalf("value %v dequeued twice", v)
+			}
+
+			seen[v] = true
+
+			prev, ok := last[v[0]]
+			if ok && prev >= v[1] {
+				t.Fatalf("values of producer %d dequeued out of order: %d after %d", v[0], v[1], prev)
+			}
+
+			last[v[0]] = v[1]
+		}
+	}
+
+	if len(seen) != Producers*PerProd {
+		t.Fatalf("expected %d values, got %d", Producers*PerProd, len(seen))
+	} else if !q.IsEmpty() || q.Size() != 0 {
+		t.Fatalf("expected the queue to be empty, got size %d", q.Size())
+	}
+}
+
+func TestStressQueue(t *testing.T) {
+	stress(t, new(Queue[[2]int]))
+}
+
+func TestStressLockFreeQueue(t *testing.T) {
+	stress(t, new(LockFreeQueue[[2]int]))
+}
+
+func TestLockFreeQueue(t *testing.T) {
+	q := new(LockFreeQueue[int])
+
+	_, err := q.Dequeue()
+	if err != ErrEmptyQueue {
+		t.Fatalf("expected %v, got %v", ErrEmptyQueue, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_ = q.Enqueue(i)
+	}
+
+	x, err := q.Peek()
+	if err != nil || x != 0 {
+		t.Fatalf("expected %d, got %d", 0, x)
+	}
+
+	for i := 0; i < 3; i++ {
+		x, err := q.Dequeue()
+		if err != nil {
+			t.Fatalf("could not dequeue %d: %v", i, err)
+		} else if x != i {
+			t.Fatalf("expected %d, got %d", i, x)
+		}
+	}
+
+	if !q.IsEmpty() {
+		t.Fatalf("expected the queue to be empty")
+	}
+}
+
+// benchmark enqueues and dequeues from every goroutine of the benchmark.
+func benchmark(b *testing.B, q Queuer[int]) {
+	b.ReportAllocs()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if i%2 == 0 {
+				_ = q.Enqueue(i)
+			} else {
+				_, _ = q.Dequeue()
+			}
+		}
+	})
+}
+
+func BenchmarkQueue(b *testing.B) {
+	benchmark(b, new(Queue[int]))
+}
+
+func BenchmarkLockFreeQueue(b *testing.B) {
+	benchmark(b, new(LockFreeQueue[int]))
+}
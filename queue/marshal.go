@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"encoding/json"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// values returns a copy of the elements of the queue that is never nil, so
+// that an empty queue is encoded as an empty list.
+//
+// Returns:
+//   - []T: The elements of the queue.
+func (queue *Queue[T]) values() []T {
+	values := queue.Slice()
+	if values == nil {
+		values = make([]T, 0)
+	}
+
+	return values
+}
+
+// replace replaces the elements of the queue. The size observers are notified
+// once.
+//
+// Parameters:
+//   - values: The new elements, from front to back.
+func (queue *Queue[T]) replace(values []T) {
+	var front, back *queue_node[T]
+
+	for _, value := range values {
+		node := &queue_node[T]{
+			value: value,
+		}
+
+		if back == nil {
+			front = node
+		} else {
+			back.next = node
+		}
+
+		back = node
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	queue.front = front
+	queue.back = back
+	queue.size = len(values)
+
+	queue.observers.Publish(queue.size)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+//
+// The queue is encoded as a list of its elements, from front to back, taken
+// from a consistent snapshot.
+func (queue *Queue[T]) MarshalJSON() ([]byte, error) {
+	if queue == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(queue.values())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+//
+// It replaces the elements of the queue with the ones of the list. The queue is
+// left unchanged if the list cannot be decoded.
+func (queue *Queue[T]) UnmarshalJSON(data []byte) error {
+	if queue == nil {
+		return common.ErrNilReceiver
+	}
+
+	var values []T
+
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return err
+	}
+
+	queue.replace(values)
+
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+//
+// The elements of the queue, from front to back, are taken from a consistent
+// snapshot and encoded with the encoding/gob package.
+func (queue *Queue[T]) MarshalBinary() ([]byte, error) {
+	if queue == nil {
+		return nil, common.ErrNilReceiver
+	}
+
+	return common.GobCodec[[]T]{}.Encode(queue.values())
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+//
+// It replaces the elements of the queue with the ones encoded by
+// MarshalBinary. The queue is left unchanged if they cannot be decoded.
+func (queue *Queue[T]) UnmarshalBinary(data []byte) error {
+	if queue == nil {
+		return common.ErrNilReceiver
+	}
+
+	values, err := common.GobCodec[[]T]{}.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	queue.replace(values)
+
+	return nil
+}
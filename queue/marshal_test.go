@@ -0,0 +1,60 @@
+package queue
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	q := new(Queue[int])
+
+	data, err := json.Marshal(q)
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	} else if string(data) != "[]" {
+		t.Fatalf("expected [], got %s", data)
+	}
+
+	_ = q.EnqueueMany([]int{1, 2, 3})
+
+	data, err = json.Marshal(q)
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	} else if string(data) != "[1,2,3]" {
+		t.Fatalf("expected [1,2,3], got %s", data)
+	}
+
+	other := new(Queue[int])
+
+	_ = other.Enqueue(9)
+
+	err = json.Unmarshal(data, other)
+	if err != nil {
+		t.Fatalf("could not unmarshal: %v", err)
+	}
+
+	got := other.Slice()
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+
+	bin, err := q.MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	}
+
+	other = new(Queue[int])
+
+	err = other.UnmarshalBinary(bin)
+	if err != nil {
+		t.Fatalf("could not unmarshal: %v", err)
+	}
+
+	_ = other.Enqueue(4)
+
+	got = other.Slice()
+	if !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Fatalf("expected [1 2 3 4], got %v", got)
+	}
+}
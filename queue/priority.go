@@ -0,0 +1,440 @@
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+
+	"github.com/PlayerR9/go-safe/common"
+	sbj "github.com/PlayerR9/go-safe/subject"
+)
+
+// Handle refers to an element pushed in a PriorityQueue. It is used to update
+// or remove the element while it is still in the queue.
+type Handle[T any] struct {
+	// value is the element.
+	value T
+
+	// seq is the insertion order of the element; it breaks priority ties.
+	seq uint64
+
+	// idx is the index of the element in the heap. -1 once it left the queue.
+	idx int
+
+	// owner is the queue of the element.
+	owner *PriorityQueue[T]
+}
+
+// Value returns the element the handle refers to. The value is the one it had
+// when it was last pushed or updated.
+//
+// Returns:
+//   - T: The element. The zero value if the receiver is nil.
+func (h *Handle[T]) Value() T {
+	if h == nil {
+		return *new(T)
+	}
+
+	h.owner.mu.RLock()
+	defer h.owner.mu.RUnlock()
+
+	return h.value
+}
+
+// handleHeap implements heap.Interface over handles.
+type handleHeap[T any] struct {
+	// handles are the handles of the heap.
+	handles []*Handle[T]
+
+	// cmp is the comparator of the elements. If nil, every element has the
+	// same priority.
+	cmp func(a, b T) int
+}
+
+// Len implements the heap.Interface interface.
+func (h handleHeap[T]) Len() int {
+	return len(h.handles)
+}
+
+// Less implements the heap.Interface interface.
+func (h handleHeap[T]) Less(i, j int) bool {
+	a, b := h.handles[i], h.handles[j]
+
+	if h.cmp != nil {
+		res := h.cmp(a.value, b.value)
+		if res != 0 {
+			return res > 0
+		}
+	}
+
+	return a.seq < b.seq
+}
+
+// Swap implements the heap.Interface interface.
+func (h handleHeap[T]) Swap(i, j int) {
+	h.handles[i], h.handles[j] = h.handles[j], h.handles[i]
+	h.handles[i].idx = i
+	h.handles[j].idx = j
+}
+
+// Push implements the heap.Interface interface.
+func (h *handleHeap[T]) Push(x any) {
+	handle := x.(*Handle[T])
+	handle.idx = len(h.handles)
+
+	h.handles = append(h.handles, handle)
+}
+
+// Pop implements the heap.Interface interface.
+func (h *handleHeap[T]) Pop() any {
+	n := len(h.handles)
+
+	handle := h.handles[n-1]
+	handle.idx = -1
+
+	h.handles[n-1] = nil
+	h.handles = h.handles[:n-1]
+
+	return handle
+}
+
+// PriorityQueue is a thread-safe queue that hands out the element with the
+// highest priority first. Elements with the same priority are handed out in
+// FIFO order. Push returns a handle that can later update or remove the
+// element.
+//
+// An empty queue whose elements all have the same priority, and so are handed
+// out in FIFO order, is created by using the `pq := new(PriorityQueue[T])`
+// constructor. NewPriorityQueue creates one that orders them by priority.
+type PriorityQueue[T any] struct {
+	// h is the heap of the elements.
+	h handleHeap[T]
+
+	// seq is the insertion order of the next element.
+	seq uint64
+
+	// changed is closed, and replaced, every time an element is pushed so that
+	// the waiters of PopWait check the queue again.
+	changed chan struct{}
+
+	// mu is the mutex that synchronizes the queue.
+	mu sync.RWMutex
+
+	// observers are the observers of the size.
+	observers sbj.Notifier[int]
+}
+
+// NewPriorityQueue creates a new, empty PriorityQueue.
+//
+// Parameters:
+//   - cmp: The comparator of the elements. A positive result means that a has
+//     a higher priority than b. If nil, the elements are handed out in FIFO
+//     order.
+//
+// Returns:
+//   - *PriorityQueue[T]: The new queue. Never returns nil.
+func NewPriorityQueue[T any](cmp func(a, b T) int) *PriorityQueue[T] {
+	return &PriorityQueue[T]{
+		h: handleHeap[T]{
+			cmp: cmp,
+		},
+	}
+}
+
+// Push adds an element to the queue.
+//
+// Parameters:
+//   - value: The element to add.
+//
+// Returns:
+//   - *Handle[T]: The handle of the element. Nil if the receiver is nil.
+//   - error: An error if the receiver is nil.
+func (pq *PriorityQueue[T]) Push(value T) (*Handle[T], error) {
+	if pq == nil {
+		return nil, common.ErrNilReceiver
+	}
+
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	handle := &Handle[T]{
+		value: value,
+		seq:   pq.seq,
+		owner: pq,
+	}
+
+	pq.seq++
+
+	heap.Push(&pq.h, handle)
+
+	if pq.changed != nil {
+		close(pq.changed)
+		pq.changed = nil
+	}
+
+	pq.observers.Publish(pq.h.Len())
+
+	return handle, nil
+}
+
+// tryPop removes the element with the highest priority if the queue is not
+// empty.
+//
+// Returns:
+//   - T: The element. The zero value if none was removed.
+//   - <-chan struct{}: Nil if the element was removed, the channel to wait on
+//     otherwise.
+func (pq *PriorityQueue[T]) tryPop() (T, <-chan struct{}) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	if pq.h.Len() == 0 {
+		if pq.changed == nil {
+			pq.changed = make(chan struct{})
+		}
+
+		return *new(T), pq.changed
+	}
+
+	handle := heap.Pop(&pq.h).(*Handle[T])
+
+	pq.observers.Publish(pq.h.Len())
+
+	return handle.value, nil
+}
+
+// Pop removes and returns the element with the highest priority.
+//
+// Returns:
+//   - T: The element.
+//   - error: An error if the queue is empty.
+//
+// Errors:
+//   - ErrEmptyQueue: If the queue is empty.
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (pq *PriorityQueue[T]) Pop() (T, error) {
+	if pq == nil {
+		return *new(T), common.ErrNilReceiver
+	}
+
+	value, wait := pq.tryPop()
+	if wait != nil {
+		return *new(T), ErrEmptyQueue
+	}
+
+	return value, nil
+}
+
+// PopWait removes and returns the element with the highest priority, waiting
+// for one if the queue is empty.
+//
+// Parameters:
+//   - ctx: The context that bounds the wait.
+//
+// Returns:
+//   - T: The element. The zero value if an error occurred.
+//   - error: An error if no element could be removed.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If ctx is nil.
+//   - context.Canceled, context.DeadlineExceeded: If the context is done
+//     before an element is available.
+func (pq *PriorityQueue[T]) PopWait(ctx context.Context) (T, error) {
+	if pq == nil {
+		return *new(T), common.ErrNilReceiver
+	} else if ctx == nil {
+		return *new(T), common.NewErrNilParam("ctx")
+	}
+
+	for {
+		value, wait := pq.tryPop()
+		if wait == nil {
+			return value, nil
+		}
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return *new(T), ctx.Err()
+		}
+	}
+}
+
+// Peek returns the element with the highest priority without removing it.
+//
+// Returns:
+//   - T: The element.
+//   - error: An error if the queue is empty.
+//
+// Errors:
+//   - ErrEmptyQueue: If the queue is empty.
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (pq *PriorityQueue[T]) Peek() (T, error) {
+	if pq == nil {
+		return *new(T), common.ErrNilReceiver
+	}
+
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+
+	if pq.h.Len() == 0 {
+		return *new(T), ErrEmptyQueue
+	}
+
+	return pq.h.handles[0].value, nil
+}
+
+// check checks whether a handle refers to an element of the queue. The caller
+// must hold the lock.
+//
+// Parameters:
+//   - handle: The handle to check.
+//
+// Returns:
+//   - error: An error if the handle is not valid.
+func (pq *PriorityQueue[T]) check(handle *Handle[T]) error {
+	if handle == nil {
+		return common.NewErrNilParam("handle")
+	} else if handle.owner != pq || handle.idx < 0 {
+		return ErrStaleHandle
+	}
+
+	return nil
+}
+
+// Update replaces the element a handle refers to and moves it according to its
+// new priority. The element keeps its place among the elements of the same
+// priority.
+//
+// Parameters:
+//   - handle: The handle of the element.
+//   - value: The new element.
+//
+// Returns:
+//   - error: An error if the element could not be updated.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If handle is nil.
+//   - ErrStaleHandle: If the element is no longer in the queue.
+func (pq *PriorityQueue[T]) Update(handle *Handle[T], value T) error {
+	if pq == nil {
+		return common.ErrNilReceiver
+	}
+
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	err := pq.check(handle)
+	if err != nil {
+		return err
+	}
+
+	handle.value = value
+
+	heap.Fix(&pq.h, handle.idx)
+
+	return nil
+}
+
+// Remove removes the element a handle refers to.
+//
+// Parameters:
+//   - handle: The handle of the element.
+//
+// Returns:
+//   - T: The removed element. The zero value if an error occurred.
+//   - error: An error if the element could not be removed.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If handle is nil.
+//   - ErrStaleHandle: If the element is no longer in the queue.
+func (pq *PriorityQueue[T]) Remove(handle *Handle[T]) (T, error) {
+	if pq == nil {
+		return *new(T), common.ErrNilReceiver
+	}
+
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	err := pq.check(handle)
+	if err != nil {
+		return *new(T), err
+	}
+
+	_ = heap.Remove(&pq.h, handle.idx)
+
+	pq.observers.Publish(pq.h.Len())
+
+	return handle.value, nil
+}
+
+// Size returns the number of elements in the queue.
+//
+// Returns:
+//   - int: The size of the queue.
+func (pq *PriorityQueue[T]) Size() int {
+	if pq == nil {
+		return 0
+	}
+
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+
+	return pq.h.Len()
+}
+
+// IsEmpty checks whether the queue is empty.
+//
+// Returns:
+//   - bool: True if the queue is empty, false otherwise.
+func (pq *PriorityQueue[T]) IsEmpty() bool {
+	return pq.Size() == 0
+}
+
+// ObserveSizeSync adds a synchronous observer to the size of the queue. It is
+// called on every change, in order, while the queue is locked; so it must be
+// cheap and must not call the methods of the queue. Does nothing if the
+// function is nil.
+//
+// Parameters:
+//   - fn: The function to be called when the size changes.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+func (pq *PriorityQueue[T]) ObserveSizeSync(fn sbj.Action[int]) error {
+	if fn == nil {
+		return nil
+	} else if pq == nil {
+		return common.ErrNilReceiver
+	}
+
+	_ = pq.observers.Observe(fn)
+
+	return nil
+}
+
+// ObserveSize adds an asynchronous observer to the size of the queue. It is
+// called from its own goroutine with the latest size, so it may call the
+// methods of the queue; the changes that happen while it runs are coalesced,
+// so it may skip intermediate sizes but always sees the last one. Use
+// ObserveSizeSync to see every change. Does nothing if the function is nil.
+//
+// Parameters:
+//   - fn: The function to be called when the size changes.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+func (pq *PriorityQueue[T]) ObserveSize(fn sbj.Action[int]) error {
+	if fn == nil {
+		return nil
+	} else if pq == nil {
+		return common.ErrNilReceiver
+	}
+
+	_ = pq.observers.ObserveCoalesced(fn)
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	type job struct {
+		name     string
+		deadline int
+	}
+
+	// The earliest deadline has the highest priority.
+	pq := NewPriorityQueue(func(a, b job) int {
+		return cmp.Compare(b.deadline, a.deadline)
+	})
+
+	_, _ = pq.Push(job{"a", 5})
+	b, _ := pq.Push(job{"b", 3})
+	c, _ := pq.Push(job{"c", 4})
+	_, _ = pq.Push(job{"d", 5})
+
+	err := pq.Update(b, job{"b", 10})
+	if err != nil {
+		t.Fatalf("could not update: %v", err)
+	}
+
+	removed, err := pq.Remove(c)
+	if err != nil || removed.name != "c" {
+		t.Fatalf("could not remove %q: %v", "c", err)
+	}
+
+	_, err = pq.Remove(c)
+	if err != ErrStaleHandle {
+		t.Fatalf("expected %v, got %v", ErrStaleHandle, err)
+	}
+
+	for _, want := range []string{"a", "d", "b"} {
+		got, err := pq.Pop()
+		if err != nil {
+			t.Fatalf("could not pop %q: %v", want, err)
+		} else if got.name != want {
+			t.Fatalf("expected %q, got %q", want, got.name)
+		}
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = pq.Push(job{"e", 1})
+	}()
+
+	got, err := pq.PopWait(context.Background())
+	if err != nil || got.name != "e" {
+		t.Fatalf("expected %q, got %q (%v)", "e", got.name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = pq.PopWait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestPriorityQueueZeroValue(t *testing.T) {
+	pq := new(PriorityQueue[int])
+
+	for _, x := range []int{3, 1, 2} {
+		_, err := pq.Push(x)
+		if err != nil {
+			t.Fatalf("could not push %d: %v", x, err)
+		}
+	}
+
+	for _, want := range []int{3, 1, 2} {
+		x, err := pq.Pop()
+		if err != nil {
+			t.Fatalf("could not pop: %v", err)
+		} else if x != want {
+			t.Fatalf("expected FIFO order, got %d instead of %d", x, want)
+		}
+	}
+}
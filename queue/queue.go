@@ -23,8 +23,11 @@ type Queue[T any] struct {
 	// concurrent reads and writes to the front and back nodes are thread-safe.
 	mu sync.RWMutex
 
-	// size is the size that observers observe.
-	size *sbj.Subject[int]
+	// size is the number of elements in the queue.
+	size int
+
+	// observers are the observers of the size.
+	observers sbj.Notifier[int]
 }
 
 // GoString implements the fmt.GoStringer interface.
@@ -36,7 +39,7 @@ func (queue *Queue[T]) GoString() string {
 	queue.mu.RLock()
 	defer queue.mu.RUnlock()
 
-	size := queue.size.MustGet()
+	size := queue.size
 	if size == 0 {
 		return "Queue[size=0, values=[]]"
 	}
@@ -84,13 +87,8 @@ func (queue *Queue[T]) Enqueue(value T) error {
 
 	queue.back = node
 
-	if queue.size == nil {
-		queue.size = new(sbj.Subject[int])
-	}
-
-	_ = queue.size.Edit(func(size *int) {
-		*size = *size + 1
-	})
+	queue.size++
+	queue.observers.Publish(queue.size)
 
 	return nil
 }
@@ -146,9 +144,8 @@ func (queue *Queue[T]) Dequeue() (T, error) {
 		queue.front = queue.front.next
 	}
 
-	_ = queue.size.Edit(func(size *int) {
-		*size = *size - 1
-	})
+	queue.size--
+	queue.observers.Publish(queue.size)
 
 	return toRemove.value, nil
 }
@@ -204,7 +201,7 @@ func (queue *Queue[T]) Size() int {
 	queue.mu.RLock()
 	defer queue.mu.RUnlock()
 
-	return queue.size.MustGet()
+	return queue.size
 }
 
 // Reset removes all elements from the queue in a safe way.
@@ -222,7 +219,9 @@ func (queue *Queue[T]) Reset() {
 
 	queue.front = nil
 	queue.back = nil
-	queue.size = nil
+	queue.size = 0
+
+	queue.observers.Publish(0)
 }
 
 // Slice returns a copy of the elements in the queue.
@@ -237,13 +236,11 @@ func (queue *Queue[T]) Slice() []T {
 	queue.mu.RLock()
 	defer queue.mu.RUnlock()
 
-	size := queue.size.MustGet()
-
-	if size == 0 {
+	if queue.size == 0 {
 		return nil
 	}
 
-	slice := make([]T, 0, size)
+	slice := make([]T, 0, queue.size)
 
 	for node := queue.front; node != nil; node = node.next {
 		slice = append(slice, node.value)
@@ -268,7 +265,7 @@ func (queue *Queue[T]) Copy() *Queue[T] {
 	defer queue.mu.RUnlock()
 
 	q_copy := &Queue[T]{
-		size: queue.size.Copy(),
+		size: queue.size,
 	}
 
 	if queue.front == nil {
@@ -296,31 +293,50 @@ func (queue *Queue[T]) Copy() *Queue[T] {
 	return q_copy
 }
 
-// ObserveSize adds an observer to the size of the queue. Does nothing if the
+// ObserveSizeSync adds a synchronous observer to the size of the queue. It is
+// called on every change, in order, while the queue is locked; so it must be
+// cheap and must not call the methods of the queue. Does nothing if the
 // function is nil.
 //
+// When there are no observers, tracking the size costs nothing more than an
+// increment.
+//
 // Parameters:
 //   - fn: The function to be called when the size changes.
 //
 // Returns:
 //   - error: An error if the receiver is nil.
-func (queue *Queue[T]) ObserveSize(fn sbj.Action[int]) error {
+func (queue *Queue[T]) ObserveSizeSync(fn sbj.Action[int]) error {
 	if fn == nil {
 		return nil
 	} else if queue == nil {
 		return common.ErrNilReceiver
 	}
 
-	o := sbj.FromAction(fn)
+	_ = queue.observers.Observe(fn)
 
-	queue.mu.Lock()
-	defer queue.mu.Unlock()
+	return nil
+}
 
-	if queue.size == nil {
-		queue.size = new(sbj.Subject[int])
+// ObserveSize adds an asynchronous observer to the size of the queue. It is
+// called from its own goroutine with the latest size, so it may call the
+// methods of the queue; the changes that happen while it runs are coalesced,
+// so it may skip intermediate sizes but always sees the last one. Use
+// ObserveSizeSync to see every change. Does nothing if the function is nil.
+//
+// Parameters:
+//   - fn: The function to be called when the size changes.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+func (queue *Queue[T]) ObserveSize(fn sbj.Action[int]) error {
+	if fn == nil {
+		return nil
+	} else if queue == nil {
+		return common.ErrNilReceiver
 	}
 
-	_ = queue.size.Attach(o)
+	_ = queue.observers.ObserveCoalesced(fn)
 
 	return nil
 }
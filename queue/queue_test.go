@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestObserveSize(t *testing.T) {
+	q := new(Queue[int])
+
+	var sizes []int
+
+	err := q.ObserveSizeSync(func(size int) error {
+		sizes = append(sizes, size)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("could not observe: %v", err)
+	}
+
+	latest := make(chan int, 100)
+
+	err = q.ObserveSize(func(size int) error {
+		latest <- size
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("could not observe: %v", err)
+	}
+
+	_ = q.Enqueue(1)
+	_ = q.Enqueue(2)
+	_, _ = q.Dequeue()
+
+	q.Reset()
+
+	_ = q.EnqueueMany([]int{3, 4, 5})
+
+	expected := []int{1, 2, 1, 0, 1, 2, 3}
+	if !slices.Equal(sizes, expected) {
+		t.Fatalf("expected %v, got %v", expected, sizes)
+	}
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case size := <-latest:
+			if size == 3 {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("expected the coalesced observer to see the last size")
+		}
+	}
+}
+
+func TestObserveSizeReentrant(t *testing.T) {
+	q := new(Queue[int])
+
+	sizes := make(chan int, 100)
+
+	err := q.ObserveSize(func(int) error {
+		sizes <- q.Size()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("could not observe: %v", err)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_ = q.Enqueue(1)
+		_ = q.Enqueue(2)
+	}()
+
+	timeout := time.After(5 * time.Second)
+
+	select {
+	case <-done:
+	case <-timeout:
+		t.Fatalf("an observer that reads the queue deadlocked Enqueue")
+	}
+
+	for {
+		select {
+		case size := <-sizes:
+			if size == 2 {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("expected the observer to see the last size")
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package queue
+
+// Queuer is the interface shared by the unbounded FIFO queues of this package,
+// so that callers can swap the implementation that suits their contention.
+type Queuer[T any] interface {
+	// Enqueue adds a value at the back of the queue.
+	//
+	// Parameters:
+	//   - value: The value to be enqueued.
+	//
+	// Returns:
+	//   - error: An error if the receiver is nil.
+	Enqueue(value T) error
+
+	// Dequeue removes and returns the front value of the queue.
+	//
+	// Returns:
+	//   - T: The front value.
+	//   - error: ErrEmptyQueue if the queue is empty.
+	Dequeue() (T, error)
+
+	// Peek returns the front value of the queue without removing it.
+	//
+	// Returns:
+	//   - T: The front value.
+	//   - error: ErrEmptyQueue if the queue is empty.
+	Peek() (T, error)
+
+	// IsEmpty checks whether the queue is empty.
+	//
+	// Returns:
+	//   - bool: True if the queue is empty, false otherwise.
+	IsEmpty() bool
+
+	// Size returns the number of values in the queue.
+	//
+	// Returns:
+	//   - int: The size of the queue.
+	Size() int
+}
@@ -0,0 +1,108 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// snapshotVersion is the version of the snapshot format.
+const snapshotVersion byte = 1
+
+// Snapshot encodes the elements of the queue, from front to back. The queue is
+// left unchanged.
+//
+// Parameters:
+//   - codec: The codec of the elements.
+//
+// Returns:
+//   - []byte: The snapshot.
+//   - error: An error if an element could not be encoded.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the codec is nil.
+//   - any other error: If an element could not be encoded.
+func (queue *Queue[T]) Snapshot(codec common.Codec[T]) ([]byte, error) {
+	if queue == nil {
+		return nil, common.ErrNilReceiver
+	} else if codec == nil {
+		return nil, common.NewErrNilParam("codec")
+	}
+
+	values := queue.Slice()
+
+	data := []byte{snapshotVersion}
+	data = binary.AppendUvarint(data, uint64(len(values)))
+
+	for i, value := range values {
+		payload, err := codec.Encode(value)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode element %d: %w", i, err)
+		}
+
+		data = common.AppendFrame(data, payload)
+	}
+
+	return data, nil
+}
+
+// Restore decodes a snapshot taken with Snapshot and enqueues its elements, in
+// order, at the back of the queue. Nothing is enqueued if the snapshot cannot be
+// decoded.
+//
+// Parameters:
+//   - data: The snapshot.
+//   - codec: The codec of the elements.
+//
+// Returns:
+//   - error: An error if the snapshot could not be decoded.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the codec is nil.
+//   - ErrBadSnapshot: If the snapshot is malformed.
+//   - any other error: If an element could not be decoded.
+func (queue *Queue[T]) Restore(data []byte, codec common.Codec[T]) error {
+	if queue == nil {
+		return common.ErrNilReceiver
+	} else if codec == nil {
+		return common.NewErrNilParam("codec")
+	}
+
+	r := bytes.NewReader(data)
+
+	version, err := r.ReadByte()
+	if err != nil || version != snapshotVersion {
+		return ErrBadSnapshot
+	}
+
+	n, err := binary.ReadUvarint(r)
+	if err != nil || n > uint64(r.Len()) {
+		return ErrBadSnapshot
+	}
+
+	values := make([]T, 0, n)
+
+	for i := uint64(0); i < n; i++ {
+		payload, ok := common.ReadFrame(r)
+		if !ok {
+			return ErrBadSnapshot
+		}
+
+		value, err := codec.Decode(payload)
+		if err != nil {
+			return fmt.Errorf("could not decode element %d: %w", i, err)
+		}
+
+		values = append(values, value)
+	}
+
+	if r.Len() != 0 {
+		return ErrBadSnapshot
+	}
+
+	return queue.EnqueueMany(values)
+}
@@ -0,0 +1,93 @@
+package queue
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+func TestSnapshot(t *testing.T) {
+	codec := common.JSONCodec[int]{}
+
+	q := new(Queue[int])
+
+	_ = q.EnqueueMany([]int{1, 2, 3})
+
+	data, err := q.Snapshot(codec)
+	if err != nil {
+		t.Fatalf("could not take snapshot: %v", err)
+	}
+
+	expected := []int{1, 2, 3}
+	if !slices.Equal(q.Slice(), expected) {
+		t.Fatalf("expected the queue to be unchanged, got %v", q.Slice())
+	}
+
+	restored := new(Queue[int])
+
+	err = restored.Restore(data, codec)
+	if err != nil {
+		t.Fatalf("could not restore snapshot: %v", err)
+	}
+
+	if !slices.Equal(restored.Slice(), expected) {
+		t.Fatalf("expected %v, got %v", expected, restored.Slice())
+	}
+
+	// Restoring into a non-empty queue appends the elements at the back.
+	err = restored.Restore(data, codec)
+	if err != nil {
+		t.Fatalf("could not restore snapshot: %v", err)
+	}
+
+	expected = []int{1, 2, 3, 1, 2, 3}
+	if !slices.Equal(restored.Slice(), expected) {
+		t.Fatalf("expected %v, got %v", expected, restored.Slice())
+	}
+}
+
+func TestRestoreMalformed(t *testing.T) {
+	codec := common.JSONCodec[int]{}
+
+	src := new(Queue[int])
+
+	_ = src.EnqueueMany([]int{1, 2, 3})
+
+	data, err := src.Snapshot(codec)
+	if err != nil {
+		t.Fatalf("could not take snapshot: %v", err)
+	}
+
+	corrupt := slices.Clone(data)
+	corrupt[len(corrupt)-1] = '?'
+
+	tests := []struct {
+		name string
+		data []byte
+		bad  bool
+	}{
+		{"empty", nil, true},
+		{"bad version", append([]byte{snapshotVersion + 1}, data[1:]...), true},
+		{"truncated", data[:len(data)-1], true},
+		{"trailing bytes", append(slices.Clone(data), 0), true},
+		{"corrupt element", corrupt, false},
+	}
+
+	for _, tt := range tests {
+		q := new(Queue[int])
+
+		_ = q.Enqueue(0)
+
+		err := q.Restore(tt.data, codec)
+		if err == nil {
+			t.Fatalf("%s: expected an error", tt.name)
+		} else if tt.bad && err != ErrBadSnapshot {
+			t.Fatalf("%s: expected %v, got %v", tt.name, ErrBadSnapshot, err)
+		}
+
+		if !slices.Equal(q.Slice(), []int{0}) {
+			t.Fatalf("%s: expected the queue to be unchanged, got %v", tt.name, q.Slice())
+		}
+	}
+}
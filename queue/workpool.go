@@ -0,0 +1,356 @@
+package queue
+
+import (
+	"context"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// workerKey is the key of the worker that runs a task in its context.
+type workerKey struct{}
+
+// worker is a worker of a WorkPool.
+type worker struct {
+	// id is the index of the worker in its pool.
+	id int
+
+	// tasks are the tasks of the worker. Only the worker pushes and pops;
+	// the other workers steal.
+	tasks WorkStealingDeque[common.Action]
+
+	// pool is the pool of the worker.
+	pool *WorkPool
+}
+
+// WorkPool runs common.Action tasks across a fixed number of workers. Every
+// worker has its own WorkStealingDeque: the tasks spawned by a running task
+// with Spawn go to the deque of its worker, and idle workers steal tasks from
+// the others. This keeps recursive parallel tasks free of lock contention.
+//
+// Tasks submitted from outside the pool go to a shared lock-free queue.
+//
+// A WorkPool must be created with NewWorkPool.
+type WorkPool struct {
+	// workers are the workers of the pool.
+	workers []*worker
+
+	// inject is the queue of the tasks submitted from outside the pool.
+	inject LockFreeQueue[common.Action]
+
+	// wake wakes idle workers up when a task is added.
+	wake chan struct{}
+
+	// pending waits for the tasks that are not done yet.
+	pending sync.WaitGroup
+
+	// running waits for the workers to stop.
+	running sync.WaitGroup
+
+	// cancel stops the workers. Nil until the pool is started.
+	cancel context.CancelFunc
+
+	// err is the first error returned by a task.
+	err error
+
+	// closed is true once the pool is closed.
+	closed bool
+
+	// mu is the mutex that synchronizes the lifecycle and the error.
+	mu sync.Mutex
+}
+
+// NewWorkPool creates a new WorkPool. It must be started with Start.
+//
+// Parameters:
+//   - n: The number of workers. If it is not positive, runtime.GOMAXPROCS(0)
+//     workers are used.
+//
+// Returns:
+//   - *WorkPool: The new pool. Never returns nil.
+func NewWorkPool(n int) *WorkPool {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+
+	p := &WorkPool{
+		workers: make([]*worker, n),
+		wake:    make(chan struct{}, n),
+	}
+
+	for i := range p.workers {
+		p.workers[i] = &worker{
+			id:   i,
+			pool: p,
+		}
+	}
+
+	return p
+}
+
+// Start starts the workers. Does nothing if the pool is already started.
+//
+// Parameters:
+//   - ctx: The context of the tasks. The workers stop once it is done.
+//
+// Returns:
+//   - error: An error if the pool could not be started.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If ctx is nil.
+//   - ErrPoolClosed: If the pool is closed.
+func (p *WorkPool) Start(ctx context.Context) error {
+	if p == nil {
+		return common.ErrNilReceiver
+	} else if ctx == nil {
+		return common.NewErrNilParam("ctx")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	} else if p.cancel != nil {
+		return nil
+	}
+
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	p.running.Add(len(p.workers))
+
+	for _, w := range p.workers {
+		go w.run(context.WithValue(ctx, workerKey{}, w))
+	}
+
+	return nil
+}
+
+// notify wakes an idle worker up, if any.
+func (p *WorkPool) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Submit adds a task to the pool. Does nothing if the task is nil.
+//
+// Parameters:
+//   - act: The task.
+//
+// Returns:
+//   - error: An error if the task could not be submitted.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrPoolClosed: If the pool is closed.
+func (p *WorkPool) Submit(act common.Action) error {
+	if act == nil {
+		return nil
+	} else if p == nil {
+		return common.ErrNilReceiver
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	p.pending.Add(1)
+
+	_ = p.inject.Enqueue(act)
+
+	p.notify()
+
+	return nil
+}
+
+// Wait waits until every task submitted or spawned so far is done.
+//
+// Returns:
+//   - error: The first error returned by a task, if any.
+func (p *WorkPool) Wait() error {
+	if p == nil {
+		return common.ErrNilReceiver
+	}
+
+	p.pending.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err
+}
+
+// Close stops the workers once they finish their current task. The tasks that
+// did not start are dropped, and no task can be submitted afterwards. Does
+// nothing if the pool is already closed.
+func (p *WorkPool) Close() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+
+	p.closed = true
+	cancel := p.cancel
+
+	p.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+
+		p.running.Wait()
+	}
+
+	for _, w := range p.workers {
+		for {
+			_, ok := w.tasks.Pop()
+			if !ok {
+				break
+			}
+
+			p.pending.Done()
+		}
+	}
+
+	for {
+		_, err := p.inject.Dequeue()
+		if err != nil {
+			break
+		}
+
+		p.pending.Done()
+	}
+}
+
+// next returns the next task of a worker: from its own deque first, then from
+// the shared queue, and finally stolen from another worker.
+//
+// Returns:
+//   - common.Action: The task.
+//   - bool: True if a task was found, false otherwise.
+func (w *worker) next() (common.Action, bool) {
+	act, ok := w.tasks.Pop()
+	if ok {
+		return act, true
+	}
+
+	act, err := w.pool.inject.Dequeue()
+	if err == nil {
+		return act, true
+	}
+
+	workers := w.pool.workers
+	offset := rand.IntN(len(workers))
+
+	for i := range workers {
+		victim := workers[(offset+i)%len(workers)]
+		if victim == w {
+			continue
+		}
+
+		act, ok := victim.tasks.Steal()
+		if ok {
+			return act, true
+		}
+	}
+
+	return nil, false
+}
+
+// run runs the tasks of the worker until the context is done.
+//
+// It must be run in a separate goroutine to avoid blocking the main thread.
+//
+// Parameters:
+//   - ctx: The context of the tasks.
+func (w *worker) run(ctx context.Context) {
+	defer w.pool.running.Done()
+
+	for {
+		act, ok := w.next()
+		if !ok {
+			select {
+			case <-w.pool.wake:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if ctx.Err() != nil {
+			// The task is dropped.
+			w.pool.pending.Done()
+			return
+		}
+
+		err := act.Run(ctx)
+		if err != nil {
+			w.pool.mu.Lock()
+
+			if w.pool.err == nil {
+				w.pool.err = err
+			}
+
+			w.pool.mu.Unlock()
+		}
+
+		w.pool.pending.Done()
+	}
+}
+
+// spawnAct is an action that adds a task to the deque of the current worker.
+type spawnAct struct {
+	// act is the task.
+	act common.Action
+}
+
+// Run implements the common.Action interface.
+func (sa *spawnAct) Run(ctx context.Context) error {
+	if ctx == nil {
+		return common.NewErrNilParam("ctx")
+	}
+
+	w, ok := ctx.Value(workerKey{}).(*worker)
+	if !ok {
+		return ErrNoWorkPool
+	}
+
+	w.pool.pending.Add(1)
+	w.tasks.Push(sa.act)
+	w.pool.notify()
+
+	return nil
+}
+
+// Spawn creates the action that adds a task to the deque of the worker running
+// the current task, so that the workers that are idle can steal it. It must be
+// run with the context of a task of a WorkPool; WorkPool.Wait then also waits
+// for the spawned task.
+//
+// Parameters:
+//   - act: The task.
+//
+// Returns:
+//   - common.Action: The spawn action. Nil if act is nil.
+func Spawn(act common.Action) common.Action {
+	if act == nil {
+		return nil
+	}
+
+	return &spawnAct{
+		act: act,
+	}
+}
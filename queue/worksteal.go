@@ -0,0 +1,178 @@
+package queue
+
+import "sync/atomic"
+
+// stealRing is the circular array of a WorkStealingDeque.
+type stealRing[T any] struct {
+	// items are the slots of the ring. Their number is a power of two.
+	items []atomic.Pointer[T]
+}
+
+// newStealRing creates a ring.
+//
+// Parameters:
+//   - size: The number of slots. It must be a power of two.
+//
+// Returns:
+//   - *stealRing[T]: The new ring. Never returns nil.
+func newStealRing[T any](size int) *stealRing[T] {
+	return &stealRing[T]{
+		items: make([]atomic.Pointer[T], size),
+	}
+}
+
+// slot returns the slot of a position.
+//
+// Parameters:
+//   - i: The position.
+//
+// Returns:
+//   - *atomic.Pointer[T]: The slot.
+func (r *stealRing[T]) slot(i int64) *atomic.Pointer[T] {
+	return &r.items[i&int64(len(r.items)-1)]
+}
+
+// grow creates a ring twice as large holding the elements in [top, bottom).
+//
+// Parameters:
+//   - top: The position of the top element.
+//   - bottom: The position after the bottom element.
+//
+// Returns:
+//   - *stealRing[T]: The new ring. Never returns nil.
+func (r *stealRing[T]) grow(top, bottom int64) *stealRing[T] {
+	bigger := newStealRing[T](2 * len(r.items))
+
+	for i := top; i < bottom; i++ {
+		bigger.slot(i).Store(r.slot(i).Load())
+	}
+
+	return bigger
+}
+
+// WorkStealingDeque is a Chase–Lev work-stealing deque. Its owner pushes and
+// pops elements at the bottom, in LIFO order, without contention, while any
+// other goroutine can steal elements from the top, in FIFO order. It never
+// takes a lock.
+//
+// Push and Pop must only be called by the owner goroutine; Steal can be called
+// by any goroutine.
+//
+// An empty deque is created by using the `d := new(WorkStealingDeque[T])`
+// constructor.
+type WorkStealingDeque[T any] struct {
+	// top is the position of the top element, where thieves steal.
+	top atomic.Int64
+
+	// bottom is the position after the bottom element, where the owner pushes
+	// and pops.
+	bottom atomic.Int64
+
+	// ring is the circular array of the elements.
+	ring atomic.Pointer[stealRing[T]]
+}
+
+// Push adds an element at the bottom of the deque. It must only be called by
+// the owner.
+//
+// Parameters:
+//   - value: The element to add.
+func (d *WorkStealingDeque[T]) Push(value T) {
+	if d == nil {
+		return
+	}
+
+	b := d.bottom.Load()
+	t := d.top.Load()
+
+	r := d.ring.Load()
+	if r == nil {
+		r = newStealRing[T](32)
+		d.ring.Store(r)
+	} else if b-t >= int64(len(r.items))-1 {
+		r = r.grow(t, b)
+		d.ring.Store(r)
+	}
+
+	r.slot(b).Store(&value)
+	d.bottom.Store(b + 1)
+}
+
+// Pop removes the bottom element of the deque, which is the element pushed
+// last. It must only be called by the owner.
+//
+// Returns:
+//   - T: The element. The zero value if none was removed.
+//   - bool: True if an element was removed, false if the deque is empty.
+func (d *WorkStealingDeque[T]) Pop() (T, bool) {
+	if d == nil {
+		return *new(T), false
+	}
+
+	b := d.bottom.Load() - 1
+	r := d.ring.Load()
+
+	d.bottom.Store(b)
+
+	t := d.top.Load()
+	if t > b {
+		d.bottom.Store(b + 1)
+		return *new(T), false
+	}
+
+	value := r.slot(b).Load()
+
+	if t == b {
+		// Last element: race against the thieves for it.
+		won := d.top.CompareAndSwap(t, t+1)
+
+		d.bottom.Store(b + 1)
+
+		if !won {
+			return *new(T), false
+		}
+	}
+
+	return *value, true
+}
+
+// Steal removes the top element of the deque, which is the oldest one. It can
+// be called by any goroutine.
+//
+// Returns:
+//   - T: The element. The zero value if none was removed.
+//   - bool: True if an element was removed, false if the deque is empty or
+//     another goroutine took the element first.
+func (d *WorkStealingDeque[T]) Steal() (T, bool) {
+	if d == nil {
+		return *new(T), false
+	}
+
+	t := d.top.Load()
+	b := d.bottom.Load()
+
+	if t >= b {
+		return *new(T), false
+	}
+
+	value := d.ring.Load().slot(t).Load()
+
+	if !d.top.CompareAndSwap(t, t+1) {
+		return *new(T), false
+	}
+
+	return *value, true
+}
+
+// Size returns the number of elements in the deque. While the deque is used
+// concurrently, the size is only an approximation.
+//
+// Returns:
+//   - int: The size of the deque.
+func (d *WorkStealingDeque[T]) Size() int {
+	if d == nil {
+		return 0
+	}
+
+	return max(int(d.bottom.Load()-d.top.Load()), 0)
+}
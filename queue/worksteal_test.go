@@ -0,0 +1,269 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+func TestStressWorkStealingDeque(t *testing.T) {
+	const (
+		Thieves int = 8
+		Total   int = 100000
+	)
+
+	d := new(WorkStealingDeque[int])
+
+	seen := make([]atomic.Int32, Total)
+	var taken atomic.Int64
+
+	take := func(v int) {
+		seen[v].Add(1)
+		taken.Add(1)
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(Thieves)
+
+	for range Thieves {
+		go func() {
+			defer wg.Done()
+
+			for taken.Load() < int64(Total) {
+				v, ok := d.Steal()
+				if ok {
+					take(v)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < Total; i++ {
+		d.Push(i)
+
+		if i%3 == 0 {
+			v, ok := d.Pop()
+			if ok {
+				take(v)
+			}
+		}
+	}
+
+	for {
+		v, ok := d.Pop()
+		if !ok {
+			break
+		}
+
+		take(v)
+	}
+
+	wg.Wait()
+
+	for i := range seen {
+		n := seen[i].Load()
+		if n != 1 {
+			t.Fatalf("expected %d to be taken once, got %d", i, n)
+		}
+	}
+
+	if d.Size() != 0 {
+		t.Fatalf("expected the deque to be empty, got %d", d.Size())
+	}
+}
+
+func TestWorkStealingDeque(t *testing.T) {
+	d := new(WorkStealingDeque[int])
+
+	for i := range 100 {
+		d.Push(i)
+	}
+
+	v, ok := d.Pop()
+	if !ok || v != 99 {
+		t.Fatalf("expected to pop 99, got %d (%t)", v, ok)
+	}
+
+	v, ok = d.Steal()
+	if !ok || v != 0 {
+		t.Fatalf("expected to steal 0, got %d (%t)", v, ok)
+	}
+
+	if d.Size() != 98 {
+		t.Fatalf("expected a size of 98, got %d", d.Size())
+	}
+}
+
+// sumAct is a task that adds the numbers in [lo, hi) to sum, splitting the
+// range with spawn until it is small enough.
+type sumAct struct {
+	lo, hi int
+	sum    *atomic.Int64
+	spawn  func(ctx context.Context, act common.Action) error
+}
+
+// Run implements the common.Action interface.
+func (sa *sumAct) Run(ctx context.Context) error {
+	if sa.hi-sa.lo <= 64 {
+		var sum int64
+
+		for i := sa.lo; i < sa.hi; i++ {
+			sum += int64(i)
+		}
+
+		sa.sum.Add(sum)
+
+		return nil
+	}
+
+	mid := (sa.lo + sa.hi) / 2
+
+	left := &sumAct{lo: sa.lo, hi: mid, sum: sa.sum, spawn: sa.spawn}
+	right := &sumAct{lo: mid, hi: sa.hi, sum: sa.sum, spawn: sa.spawn}
+
+	err := sa.spawn(ctx, left)
+	if err != nil {
+		return err
+	}
+
+	return right.Run(ctx)
+}
+
+// spawnInPool spawns a task on the WorkPool running the context.
+func spawnInPool(ctx context.Context, act common.Action) error {
+	return Spawn(act).Run(ctx)
+}
+
+func TestWorkPool(t *testing.T) {
+	const N int = 100000
+
+	p := NewWorkPool(4)
+	defer p.Close()
+
+	err := p.Start(context.Background())
+	if err != nil {
+		t.Fatalf("could not start the pool: %v", err)
+	}
+
+	var sum atomic.Int64
+
+	err = p.Submit(&sumAct{lo: 0, hi: N, sum: &sum, spawn: spawnInPool})
+	if err != nil {
+		t.Fatalf("could not submit: %v", err)
+	}
+
+	err = p.Wait()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := int64(N * (N - 1) / 2)
+	if sum.Load() != want {
+		t.Fatalf("expected a sum of %d, got %d", want, sum.Load())
+	}
+
+	err = Spawn(&sumAct{sum: &sum}).Run(context.Background())
+	if !errors.Is(err, ErrNoWorkPool) {
+		t.Fatalf("expected %v, got %v", ErrNoWorkPool, err)
+	}
+
+	p.Close()
+
+	err = p.Submit(&sumAct{sum: &sum})
+	if !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected %v, got %v", ErrPoolClosed, err)
+	}
+
+	err = p.Wait()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+// sharedPool runs tasks with workers that share a single locked Queue.
+type sharedPool struct {
+	tasks   Queue[common.Action]
+	pending sync.WaitGroup
+}
+
+// spawn adds a task to the shared queue.
+func (sp *sharedPool) spawn(_ context.Context, act common.Action) error {
+	sp.pending.Add(1)
+
+	return sp.tasks.Enqueue(act)
+}
+
+// run runs tasks until done is closed.
+func (sp *sharedPool) run(ctx context.Context, done <-chan struct{}) {
+	for {
+		act, err := sp.tasks.Dequeue()
+		if err != nil {
+			select {
+			case <-done:
+				return
+			default:
+				runtime.Gosched()
+				continue
+			}
+		}
+
+		_ = act.Run(ctx)
+
+		sp.pending.Done()
+	}
+}
+
+func BenchmarkWorkPool(b *testing.B) {
+	p := NewWorkPool(0)
+	defer p.Close()
+
+	_ = p.Start(context.Background())
+
+	var sum atomic.Int64
+
+	b.ReportAllocs()
+
+	for range b.N {
+		_ = p.Submit(&sumAct{lo: 0, hi: 1 << 16, sum: &sum, spawn: spawnInPool})
+		_ = p.Wait()
+	}
+}
+
+func BenchmarkSharedQueuePool(b *testing.B) {
+	sp := new(sharedPool)
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+
+	for range runtime.GOMAXPROCS(0) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			sp.run(context.Background(), done)
+		}()
+	}
+
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+
+	var sum atomic.Int64
+
+	b.ReportAllocs()
+
+	for range b.N {
+		_ = sp.spawn(context.Background(), &sumAct{lo: 0, hi: 1 << 16, sum: &sum, spawn: sp.spawn})
+		sp.pending.Wait()
+	}
+}
@@ -0,0 +1,204 @@
+package rws
+
+// LoadOrStore returns the value of a key if it exists. Otherwise, it sets the
+// key to the given value.
+//
+// Parameters:
+//   - key: The key.
+//   - val: The value to set if the key does not exist.
+//
+// Returns:
+//   - U: The value of the key after the call.
+//   - bool: True if the key existed, false if the value was stored or the
+//     receiver is nil.
+func (sm *Map[T, U]) LoadOrStore(key T, val U) (U, bool) {
+	if sm == nil {
+		return *new(U), false
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	old, ok := sm.m[key]
+	if ok {
+		return old, true
+	}
+
+	if sm.m == nil {
+		sm.m = make(map[T]U)
+	}
+
+	sm.m[key] = val
+
+	return val, false
+}
+
+// SetIfAbsent sets a key to a value only if the key does not exist.
+//
+// Parameters:
+//   - key: The key.
+//   - val: The value to set.
+//
+// Returns:
+//   - bool: True if the value was set, false otherwise.
+func (sm *Map[T, U]) SetIfAbsent(key T, val U) bool {
+	if sm == nil {
+		return false
+	}
+
+	_, loaded := sm.LoadOrStore(key, val)
+
+	return !loaded
+}
+
+// LoadAndDelete removes a key from the map and returns its value.
+//
+// Parameters:
+//   - key: The key to remove.
+//
+// Returns:
+//   - U: The value of the key. The zero value if it did not exist.
+//   - bool: True if the key existed, false otherwise.
+func (sm *Map[T, U]) LoadAndDelete(key T) (U, bool) {
+	if sm == nil {
+		return *new(U), false
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	old, ok := sm.m[key]
+	if ok {
+		delete(sm.m, key)
+	}
+
+	return old, ok
+}
+
+// CompareAndSwap sets a key to a new value only if its current value is equal
+// to the old one.
+//
+// Parameters:
+//   - key: The key.
+//   - old: The expected value.
+//   - val: The new value.
+//   - eq: The function that compares two values. It is called while the map is
+//     locked, so it must not call the methods of the map.
+//
+// Returns:
+//   - bool: True if the value was swapped, false if the key does not exist, its
+//     value is not equal to old, or eq is nil.
+func (sm *Map[T, U]) CompareAndSwap(key T, old, val U, eq func(a, b U) bool) bool {
+	if sm == nil || eq == nil {
+		return false
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	cur, ok := sm.m[key]
+	if !ok || !eq(cur, old) {
+		return false
+	}
+
+	sm.m[key] = val
+
+	return true
+}
+
+// Compute computes the new value of a key from its current value. If fn is
+// nil, the map is left unchanged and the current value is returned.
+//
+// Parameters:
+//   - key: The key.
+//   - fn: The function that returns the new value and whether the key is kept;
+//     if not, the key is removed. It is called with the current value, or the
+//     zero value, and whether the key exists. It is called while the map is
+//     locked, so it must not call the methods of the map.
+//
+// Returns:
+//   - U: The new value. The zero value if the key was removed.
+//   - bool: True if the key is in the map after the call, false otherwise.
+func (sm *Map[T, U]) Compute(key T, fn func(old U, exists bool) (U, bool)) (U, bool) {
+	if sm == nil {
+		return *new(U), false
+	} else if fn == nil {
+		return sm.Get(key)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	old, exists := sm.m[key]
+
+	val, keep := fn(old, exists)
+	if !keep {
+		delete(sm.m, key)
+		return *new(U), false
+	}
+
+	if sm.m == nil {
+		sm.m = make(map[T]U)
+	}
+
+	sm.m[key] = val
+
+	return val, true
+}
+
+// Update updates the value of a key only if the key exists. Does nothing if fn
+// is nil.
+//
+// Parameters:
+//   - key: The key.
+//   - fn: The function that returns the new value from the current one. It is
+//     called while the map is locked, so it must not call the methods of the
+//     map.
+//
+// Returns:
+//   - bool: True if the value was updated, false if the key does not exist.
+func (sm *Map[T, U]) Update(key T, fn func(old U) U) bool {
+	if sm == nil || fn == nil {
+		return false
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	old, ok := sm.m[key]
+	if !ok {
+		return false
+	}
+
+	sm.m[key] = fn(old)
+
+	return true
+}
+
+// DeleteIf removes every entry of the map for which the predicate returns true.
+//
+// Parameters:
+//   - pred: The predicate. It is called while the map is locked, so it must not
+//     call the methods of the map.
+//
+// Returns:
+//   - int: The number of removed entries. 0 if the receiver or pred is nil.
+func (sm *Map[T, U]) DeleteIf(pred func(key T, val U) bool) int {
+	if sm == nil || pred == nil {
+		return 0
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	var n int
+
+	for key, val := range sm.m {
+		if pred(key, val) {
+			delete(sm.m, key)
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,103 @@
+package rws
+
+import (
+	"maps"
+	"sync"
+	"testing"
+)
+
+func TestCompound(t *testing.T) {
+	m := new(Map[string, int])
+
+	v, loaded := m.LoadOrStore("a", 1)
+	if loaded || v != 1 {
+		t.Fatalf("expected to store 1, got %d (%t)", v, loaded)
+	}
+
+	v, loaded = m.LoadOrStore("a", 2)
+	if !loaded || v != 1 {
+		t.Fatalf("expected to load 1, got %d (%t)", v, loaded)
+	}
+
+	if m.SetIfAbsent("a", 3) || !m.SetIfAbsent("b", 3) {
+		t.Fatalf("expected SetIfAbsent to set b only")
+	}
+
+	eq := func(a, b int) bool { return a == b }
+
+	if m.CompareAndSwap("a", 5, 6, eq) || !m.CompareAndSwap("a", 1, 6, eq) {
+		t.Fatalf("expected CompareAndSwap to swap 1 only")
+	}
+
+	if !m.Update("b", func(old int) int { return old * 10 }) || m.Update("c", func(old int) int { return old }) {
+		t.Fatalf("expected Update to update b only")
+	}
+
+	v, ok := m.Compute("c", func(old int, exists bool) (int, bool) { return old + 7, !exists })
+	if !ok || v != 7 {
+		t.Fatalf("expected Compute to set c to 7, got %d (%t)", v, ok)
+	}
+
+	v, ok = m.Compute("c", nil)
+	if !ok || v != 7 {
+		t.Fatalf("expected Compute without a function to return 7, got %d (%t)", v, ok)
+	}
+
+	_, ok = m.Compute("c", func(old int, exists bool) (int, bool) { return 0, false })
+	if ok {
+		t.Fatalf("expected Compute to remove c")
+	}
+
+	if !maps.Equal(m.GetMap(), map[string]int{"a": 6, "b": 30}) {
+		t.Fatalf("expected map[a:6 b:30], got %v", m.GetMap())
+	}
+
+	v, ok = m.LoadAndDelete("a")
+	if !ok || v != 6 {
+		t.Fatalf("expected to delete a, got %d (%t)", v, ok)
+	}
+
+	n := m.DeleteIf(func(_ string, v int) bool { return v > 10 })
+	if n != 1 || m.Len() != 0 {
+		t.Fatalf("expected DeleteIf to empty the map, removed %d", n)
+	}
+}
+
+func TestComputeCounter(t *testing.T) {
+	const (
+		Workers int = 8
+		PerW    int = 1000
+	)
+
+	m := new(Map[string, int])
+	sm := new(ShardedMap[string, int])
+
+	incr := func(old int, _ bool) (int, bool) { return old + 1, true }
+
+	var wg sync.WaitGroup
+
+	wg.Add(Workers)
+
+	for range Workers {
+		go func() {
+			defer wg.Done()
+
+			for range PerW {
+				_, _ = m.Compute("n", incr)
+				_, _ = sm.Compute("n", incr)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	v, _ := m.Get("n")
+	if v != Workers*PerW {
+		t.Fatalf("expected %d, got %d", Workers*PerW, v)
+	}
+
+	v, _ = sm.Get("n")
+	if v != Workers*PerW {
+		t.Fatalf("expected %d, got %d", Workers*PerW, v)
+	}
+}
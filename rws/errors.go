@@ -0,0 +1,16 @@
+package rws
+
+import "errors"
+
+var (
+	// ErrMapClosed occurs when an observer is added to a TTLMap that is
+	// closed.
+	//
+	// Format:
+	//   "map is closed"
+	ErrMapClosed error
+)
+
+func init() {
+	ErrMapClosed = errors.New("map is closed")
+}
@@ -0,0 +1,121 @@
+package rws
+
+import (
+	"sync"
+	"testing"
+)
+
+// hammer runs fn in a loop from several goroutines until stop is closed.
+func hammer(stop <-chan struct{}, wg *sync.WaitGroup, fn func(i int)) {
+	for range 4 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				fn(i)
+			}
+		}()
+	}
+}
+
+func TestMapEntryRace(t *testing.T) {
+	m := new(Map[int, int])
+
+	for i := range 100 {
+		_ = m.Set(i, i)
+	}
+
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+
+	hammer(stop, &wg, func(i int) {
+		_ = m.Set(i%200, i)
+		m.Delete((i + 100) % 200)
+	})
+
+	for range 100 {
+		for key, value := range m.EntrySnapshot() {
+			_ = key + value
+		}
+
+		for key, value := range m.EntryLocked() {
+			_ = key + value
+		}
+
+		for range m.Entry() {
+			break
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestShardedMapEntryRace(t *testing.T) {
+	sm := NewShardedMap[int, int](4, nil)
+
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+
+	hammer(stop, &wg, func(i int) {
+		_ = sm.Set(i%200, i)
+		sm.Delete((i + 100) % 200)
+	})
+
+	for range 100 {
+		for key, value := range sm.EntrySnapshot() {
+			_ = key + value
+		}
+
+		for key, value := range sm.EntryLocked() {
+			_ = key + value
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestTableRowRace(t *testing.T) {
+	tbl, err := NewTable[int](8, 8)
+	if err != nil {
+		t.Fatalf("could not create the table: %v", err)
+	}
+
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+
+	hammer(stop, &wg, func(i int) {
+		tbl.SetCellAt(i, i%8, (i/8)%8)
+	})
+
+	for range 100 {
+		for _, row := range tbl.RowSnapshot() {
+			row[0] = -1
+		}
+
+		for _, row := range tbl.RowLocked() {
+			row[0] = -1
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+
+	for _, row := range tbl.Row() {
+		if row[0] == -1 {
+			t.Fatalf("expected the rows handed out to be copies")
+		}
+	}
+}
@@ -39,27 +39,52 @@ func (sm *Map[T, U]) Copy() *Map[T, U] {
 	}
 }
 
-// Entry is a method that returns an iterator over the entries in the Map.
+// Entry returns an iterator over the entries in the Map. It is the same as
+// EntrySnapshot.
 //
 // Returns:
 //   - iter.Seq2[T, U]: An iterator over the entries in the Map. Never returns nil.
 func (sm *Map[T, U]) Entry() iter.Seq2[T, U] {
-	if sm == nil {
-		return func(yield func(T, U) bool) {}
+	return sm.EntrySnapshot()
+}
+
+// EntrySnapshot returns an iterator over a copy of the entries in the Map,
+// taken when the iteration starts. The Map can be modified while iterating.
+//
+// Returns:
+//   - iter.Seq2[T, U]: An iterator over the entries in the Map. Never returns nil.
+func (sm *Map[T, U]) EntrySnapshot() iter.Seq2[T, U] {
+	return func(yield func(T, U) bool) {
+		for key, value := range sm.GetMap() {
+			if !yield(key, value) {
+				return
+			}
+		}
 	}
+}
 
-	sm.mu.RLock()
-	defer sm.mu.RUnlock()
+// EntryLocked returns an iterator over the entries in the Map that holds the
+// read lock for the whole iteration. It does not copy the Map, but the other
+// goroutines cannot modify it until the loop ends; and the body of the loop must
+// not modify it either, or it deadlocks.
+//
+// Returns:
+//   - iter.Seq2[T, U]: An iterator over the entries in the Map. Never returns nil.
+func (sm *Map[T, U]) EntryLocked() iter.Seq2[T, U] {
+	return func(yield func(T, U) bool) {
+		if sm == nil {
+			return
+		}
+
+		sm.mu.RLock()
+		defer sm.mu.RUnlock()
 
-	fn := func(yield func(T, U) bool) {
 		for key, value := range sm.m {
 			if !yield(key, value) {
 				return
 			}
 		}
 	}
-
-	return fn
 }
 
 // Get retrieves a value from the map.
@@ -71,7 +96,7 @@ func (sm *Map[T, U]) Entry() iter.Seq2[T, U] {
 //   - U: The value associated with the key.
 //   - bool: A boolean indicating if the key exists in the map.
 func (sm *Map[T, U]) Get(key T) (U, bool) {
-	if sm == nil || len(sm.m) == 0 {
+	if sm == nil {
 		return *new(U), false
 	}
 
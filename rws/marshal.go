@@ -0,0 +1,339 @@
+package rws
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// MarshalJSON implements the json.Marshaler interface.
+//
+// The value of the variable is encoded as is.
+func (s *Var[T]) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(s.MustGet())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+//
+// The variable is left unchanged if the value cannot be decoded.
+func (s *Var[T]) UnmarshalJSON(data []byte) error {
+	if s == nil {
+		return common.ErrNilReceiver
+	}
+
+	var value T
+
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return err
+	}
+
+	return s.Set(value)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+//
+// The value of the variable is encoded with the encoding/gob package.
+func (s *Var[T]) MarshalBinary() ([]byte, error) {
+	if s == nil {
+		return nil, common.ErrNilReceiver
+	}
+
+	return common.GobCodec[T]{}.Encode(s.MustGet())
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+//
+// The variable is left unchanged if the value cannot be decoded.
+func (s *Var[T]) UnmarshalBinary(data []byte) error {
+	if s == nil {
+		return common.ErrNilReceiver
+	}
+
+	value, err := common.GobCodec[T]{}.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	return s.Set(value)
+}
+
+// set replaces the elements of the Slice.
+//
+// Parameters:
+//   - slice: The new elements.
+func (s *Slice[T]) set(slice []T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.slice = slice
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+//
+// The Slice is encoded as a list of its elements, taken from a consistent
+// snapshot.
+func (s *Slice[T]) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(s.Slice())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+//
+// It replaces the elements of the Slice with the ones of the list. The Slice is
+// left unchanged if the list cannot be decoded.
+func (s *Slice[T]) UnmarshalJSON(data []byte) error {
+	if s == nil {
+		return common.ErrNilReceiver
+	}
+
+	var slice []T
+
+	err := json.Unmarshal(data, &slice)
+	if err != nil {
+		return err
+	}
+
+	s.set(slice)
+
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+//
+// The elements of the Slice are taken from a consistent snapshot and encoded
+// with the encoding/gob package.
+func (s *Slice[T]) MarshalBinary() ([]byte, error) {
+	if s == nil {
+		return nil, common.ErrNilReceiver
+	}
+
+	return common.GobCodec[[]T]{}.Encode(s.Slice())
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+//
+// It replaces the elements of the Slice with the ones encoded by
+// MarshalBinary. The Slice is left unchanged if they cannot be decoded.
+func (s *Slice[T]) UnmarshalBinary(data []byte) error {
+	if s == nil {
+		return common.ErrNilReceiver
+	}
+
+	slice, err := common.GobCodec[[]T]{}.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	s.set(slice)
+
+	return nil
+}
+
+// set replaces the entries of the map.
+//
+// Parameters:
+//   - m: The new entries.
+func (sm *Map[T, U]) set(m map[T]U) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.m = m
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+//
+// The map is encoded as an object taken from a consistent snapshot. Its keys
+// must be strings, integers or implement the encoding.TextMarshaler interface.
+func (sm *Map[T, U]) MarshalJSON() ([]byte, error) {
+	if sm == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(sm.GetMap())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+//
+// It replaces the entries of the map with the ones of the object. The map is
+// left unchanged if the object cannot be decoded.
+func (sm *Map[T, U]) UnmarshalJSON(data []byte) error {
+	if sm == nil {
+		return common.ErrNilReceiver
+	}
+
+	var m map[T]U
+
+	err := json.Unmarshal(data, &m)
+	if err != nil {
+		return err
+	}
+
+	sm.set(m)
+
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+//
+// The entries of the map are taken from a consistent snapshot and encoded with
+// the encoding/gob package.
+func (sm *Map[T, U]) MarshalBinary() ([]byte, error) {
+	if sm == nil {
+		return nil, common.ErrNilReceiver
+	}
+
+	return common.GobCodec[map[T]U]{}.Encode(sm.GetMap())
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+//
+// It replaces the entries of the map with the ones encoded by MarshalBinary.
+// The map is left unchanged if they cannot be decoded.
+func (sm *Map[T, U]) UnmarshalBinary(data []byte) error {
+	if sm == nil {
+		return common.ErrNilReceiver
+	}
+
+	m, err := common.GobCodec[map[T]U]{}.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	sm.set(m)
+
+	return nil
+}
+
+// tableData is the encoded form of a Table.
+type tableData[T any] struct {
+	// Width is the width of the table.
+	Width int `json:"width"`
+
+	// Height is the height of the table.
+	Height int `json:"height"`
+
+	// Rows are the rows of the table.
+	Rows [][]T `json:"rows"`
+}
+
+// snapshot takes a consistent snapshot of the table.
+//
+// Returns:
+//   - tableData[T]: The snapshot.
+func (t *Table[T]) snapshot() tableData[T] {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rows := make([][]T, 0, t.height)
+
+	for _, row := range t.table {
+		rows = append(rows, append(make([]T, 0, t.width), row...))
+	}
+
+	return tableData[T]{
+		Width:  t.width,
+		Height: t.height,
+		Rows:   rows,
+	}
+}
+
+// restore replaces the cells of the table with the ones of a snapshot.
+//
+// Parameters:
+//   - data: The snapshot.
+//
+// Returns:
+//   - error: An error if the snapshot does not have the shape it claims.
+func (t *Table[T]) restore(data tableData[T]) error {
+	if data.Width < 0 || data.Height < 0 || len(data.Rows) != data.Height {
+		return fmt.Errorf("expected %d rows, got %d", data.Height, len(data.Rows))
+	}
+
+	table := make([][]T, 0, data.Height)
+
+	for i, row := range data.Rows {
+		if len(row) != data.Width {
+			return fmt.Errorf("expected %d cells in row %d, got %d", data.Width, i, len(row))
+		}
+
+		table = append(table, append(make([]T, 0, data.Width), row...))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.table = table
+	t.width = data.Width
+	t.height = data.Height
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+//
+// The table is encoded as an object with its width, its height and its rows,
+// taken from a consistent snapshot.
+func (t *Table[T]) MarshalJSON() ([]byte, error) {
+	if t == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(t.snapshot())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+//
+// It replaces the cells of the table with the ones of the object. The table is
+// left unchanged if the object cannot be decoded or has the wrong shape.
+func (t *Table[T]) UnmarshalJSON(data []byte) error {
+	if t == nil {
+		return common.ErrNilReceiver
+	}
+
+	var td tableData[T]
+
+	err := json.Unmarshal(data, &td)
+	if err != nil {
+		return err
+	}
+
+	return t.restore(td)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+//
+// The width, the height and the rows of the table are taken from a consistent
+// snapshot and encoded with the encoding/gob package.
+func (t *Table[T]) MarshalBinary() ([]byte, error) {
+	if t == nil {
+		return nil, common.ErrNilReceiver
+	}
+
+	return common.GobCodec[tableData[T]]{}.Encode(t.snapshot())
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+//
+// It replaces the cells of the table with the ones encoded by MarshalBinary.
+// The table is left unchanged if they cannot be decoded.
+func (t *Table[T]) UnmarshalBinary(data []byte) error {
+	if t == nil {
+		return common.ErrNilReceiver
+	}
+
+	td, err := common.GobCodec[tableData[T]]{}.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	return t.restore(td)
+}
@@ -0,0 +1,92 @@
+package rws
+
+import (
+	"encoding"
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+)
+
+// container is a container that can be marshalled.
+type container interface {
+	json.Marshaler
+	json.Unmarshaler
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// roundTrip marshals src and unmarshals it into a JSON and a binary
+// destination, which are returned in that order.
+func roundTrip[C container](t *testing.T, src C, newDst func() C) []C {
+	t.Helper()
+
+	data, err := json.Marshal(src)
+	if err != nil {
+		t.Fatalf("could not marshal to JSON: %v", err)
+	}
+
+	fromJSON := newDst()
+
+	err = json.Unmarshal(data, fromJSON)
+	if err != nil {
+		t.Fatalf("could not unmarshal %s: %v", data, err)
+	}
+
+	data, err = src.MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not marshal to binary: %v", err)
+	}
+
+	fromBinary := newDst()
+
+	err = fromBinary.UnmarshalBinary(data)
+	if err != nil {
+		t.Fatalf("could not unmarshal binary: %v", err)
+	}
+
+	return []C{fromJSON, fromBinary}
+}
+
+func TestMarshal(t *testing.T) {
+	v := New("hello")
+
+	for _, got := range roundTrip(t, v, func() *Var[string] { return new(Var[string]) }) {
+		if got.MustGet() != "hello" {
+			t.Fatalf("expected hello, got %q", got.MustGet())
+		}
+	}
+
+	s := new(Slice[int])
+	_ = s.Append(1, 2, 3)
+
+	for _, got := range roundTrip(t, s, func() *Slice[int] { return new(Slice[int]) }) {
+		if !slices.Equal(got.Slice(), []int{1, 2, 3}) {
+			t.Fatalf("expected [1 2 3], got %v", got.Slice())
+		}
+	}
+
+	m := new(Map[string, int])
+	_ = m.Set("a", 1)
+	_ = m.Set("b", 2)
+
+	for _, got := range roundTrip(t, m, func() *Map[string, int] { return new(Map[string, int]) }) {
+		if !maps.Equal(got.GetMap(), map[string]int{"a": 1, "b": 2}) {
+			t.Fatalf("expected map[a:1 b:2], got %v", got.GetMap())
+		}
+	}
+
+	tbl, _ := NewTable[int](3, 2)
+	tbl.SetCellAt(5, 2, 1)
+
+	for _, got := range roundTrip(t, tbl, func() *Table[int] { return new(Table[int]) }) {
+		if got.Width() != 3 || got.Height() != 2 || got.CellAt(2, 1) != 5 {
+			t.Fatalf("expected a 3x2 table with 5 at (2, 1), got %dx%d", got.Width(), got.Height())
+		}
+	}
+
+	err := new(Table[int]).UnmarshalJSON([]byte(`{"width":2,"height":1,"rows":[[1]]}`))
+	if err == nil {
+		t.Fatalf("expected an error for a malformed table")
+	}
+}
@@ -0,0 +1,442 @@
+package rws
+
+import (
+	"hash/maphash"
+	"iter"
+	"math/bits"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// seed is the seed of the default hash function of the sharded maps.
+var seed = maphash.MakeSeed()
+
+// mix scrambles the bits of an integer key with the finalizer of SplitMix64.
+//
+// Parameters:
+//   - x: The key.
+//
+// Returns:
+//   - uint64: The hash of the key.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return x
+}
+
+// defaultHash returns the default hash function of a key type: strings are
+// hashed with the maphash package, and integers and booleans with mix. Types
+// whose underlying type is one of those are hashed through reflection, which
+// is slower.
+//
+// Other key types, such as floats, have no default hash: keys that are equal,
+// such as 0.0 and -0.0, could get different hashes and so end up in different
+// shards.
+//
+// Returns:
+//   - func(key K) uint64: The hash function. Nil if the key type is not
+//     supported.
+func defaultHash[K comparable]() func(key K) uint64 {
+	switch any(*new(K)).(type) {
+	case string, int, int64, int32, int16, int8, uint, uint64, uint32, uint16, uint8, uintptr, bool:
+		return func(key K) uint64 {
+			switch k := any(key).(type) {
+			case string:
+				return maphash.String(seed, k)
+			case int:
+				return mix(uint64(k))
+			case int64:
+				return mix(uint64(k))
+			case int32:
+				return mix(uint64(k))
+			case int16:
+				return mix(uint64(k))
+			case int8:
+				return mix(uint64(k))
+			case uint:
+				return mix(uint64(k))
+			case uint64:
+				return mix(k)
+			case uint32:
+				return mix(uint64(k))
+			case uint16:
+				return mix(uint64(k))
+			case uint8:
+				return mix(uint64(k))
+			case uintptr:
+				return mix(uint64(k))
+			default:
+				if any(key).(bool) {
+					return mix(1)
+				}
+
+				return mix(0)
+			}
+		}
+	}
+
+	switch reflect.TypeFor[K]().Kind() {
+	case reflect.String:
+		return func(key K) uint64 {
+			return maphash.String(seed, reflect.ValueOf(key).String())
+		}
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		return func(key K) uint64 {
+			return mix(uint64(reflect.ValueOf(key).Int()))
+		}
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8, reflect.Uintptr:
+		return func(key K) uint64 {
+			return mix(reflect.ValueOf(key).Uint())
+		}
+	case reflect.Bool:
+		return func(key K) uint64 {
+			if reflect.ValueOf(key).Bool() {
+				return mix(1)
+			}
+
+			return mix(0)
+		}
+	default:
+		return nil
+	}
+}
+
+// shard is a shard of a ShardedMap.
+type shard[K comparable, V any] struct {
+	Map[K, V]
+
+	// _ keeps the shards on separate cache lines.
+	_ [64]byte
+}
+
+// ShardedMap is a thread-safe map split into shards, each guarded by its own
+// mutex, so that goroutines writing to different keys rarely contend. It offers
+// the same API as Map.
+//
+// A map with the default configuration is created by using the
+// `sm := new(rws.ShardedMap[K, V])` constructor, and a configured one with
+// NewShardedMap. The default configuration only shards the keys that are
+// strings, integers or booleans; a map with any other key type and no hash
+// function keeps all of its keys in a single shard.
+type ShardedMap[K comparable, V any] struct {
+	// shards are the shards of the map. Their number is a power of two.
+	shards []shard[K, V]
+
+	// hash is the hash function of the keys.
+	hash func(key K) uint64
+
+	// once initializes the map when it is created with new.
+	once sync.Once
+}
+
+// NewShardedMap creates a new ShardedMap.
+//
+// Parameters:
+//   - shards: The number of shards, rounded up to a power of two. If it is not
+//     positive, 4 * runtime.GOMAXPROCS(0) shards are used.
+//   - hash: The hash function of the keys. Keys that are equal must have the
+//     same hash. If nil, strings, integers and booleans get a default hash,
+//     and any other key type, such as floats or structs, uses a single shard
+//     since equal keys could otherwise end up in different shards.
+//
+// Returns:
+//   - *ShardedMap[K, V]: The new map. Never returns nil.
+func NewShardedMap[K comparable, V any](shards int, hash func(key K) uint64) *ShardedMap[K, V] {
+	sm := new(ShardedMap[K, V])
+
+	sm.once.Do(func() {
+		sm.init(shards, hash)
+	})
+
+	return sm
+}
+
+// init initializes the map.
+//
+// Parameters:
+//   - n: The number of shards.
+//   - hash: The hash function of the keys.
+func (sm *ShardedMap[K, V]) init(n int, hash func(key K) uint64) {
+	if n <= 0 {
+		n = 4 * runtime.GOMAXPROCS(0)
+	}
+
+	if hash == nil {
+		hash = defaultHash[K]()
+	}
+
+	if hash == nil {
+		// Without a hash that agrees with ==, every key goes to the same shard.
+		n = 1
+		hash = func(K) uint64 { return 0 }
+	}
+
+	sm.shards = make([]shard[K, V], 1<<bits.Len(uint(n-1)))
+	sm.hash = hash
+}
+
+// shardOf returns the shard of a key.
+//
+// Parameters:
+//   - key: The key.
+//
+// Returns:
+//   - *Map[K, V]: The shard of the key. Never returns nil.
+func (sm *ShardedMap[K, V]) shardOf(key K) *Map[K, V] {
+	sm.once.Do(func() {
+		sm.init(0, nil)
+	})
+
+	h := sm.hash(key)
+
+	return &sm.shards[h&uint64(len(sm.shards)-1)].Map
+}
+
+// Get retrieves a value from the map.
+//
+// Parameters:
+//   - key: The key to retrieve the value.
+//
+// Returns:
+//   - V: The value associated with the key.
+//   - bool: A boolean indicating if the key exists in the map.
+func (sm *ShardedMap[K, V]) Get(key K) (V, bool) {
+	if sm == nil {
+		return *new(V), false
+	}
+
+	return sm.shardOf(key).Get(key)
+}
+
+// Set sets a value in the map.
+//
+// Parameters:
+//   - key: The key to set the value.
+//   - val: The value to set.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+func (sm *ShardedMap[K, V]) Set(key K, val V) error {
+	if sm == nil {
+		return common.ErrNilReceiver
+	}
+
+	return sm.shardOf(key).Set(key, val)
+}
+
+// Delete removes a key from the map. Does nothing if the key does not exist in
+// the map.
+//
+// Parameters:
+//   - key: The key to remove.
+func (sm *ShardedMap[K, V]) Delete(key K) {
+	if sm == nil {
+		return
+	}
+
+	sm.shardOf(key).Delete(key)
+}
+
+// all returns the shards of the map.
+//
+// Returns:
+//   - []shard[K, V]: The shards. Never returns nil.
+func (sm *ShardedMap[K, V]) all() []shard[K, V] {
+	sm.once.Do(func() {
+		sm.init(0, nil)
+	})
+
+	return sm.shards
+}
+
+// Len returns the number of elements in the map. While the map is modified
+// concurrently, the shards are counted one after the other.
+//
+// Returns:
+//   - int: The number of elements in the map. 0 if the receiver is nil.
+func (sm *ShardedMap[K, V]) Len() int {
+	if sm == nil {
+		return 0
+	}
+
+	var n int
+
+	shards := sm.all()
+
+	for i := range shards {
+		n += shards[i].Len()
+	}
+
+	return n
+}
+
+// Entry returns an iterator over the entries in the map. It is the same as
+// EntrySnapshot.
+//
+// Returns:
+//   - iter.Seq2[K, V]: An iterator over the entries in the map. Never returns
+//     nil.
+func (sm *ShardedMap[K, V]) Entry() iter.Seq2[K, V] {
+	return sm.EntrySnapshot()
+}
+
+// EntrySnapshot returns an iterator over the entries in the map. Each shard is
+// copied when the iteration reaches it, so the map can be modified while
+// iterating.
+//
+// Returns:
+//   - iter.Seq2[K, V]: An iterator over the entries in the map. Never returns
+//     nil.
+func (sm *ShardedMap[K, V]) EntrySnapshot() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if sm == nil {
+			return
+		}
+
+		shards := sm.all()
+
+		for i := range shards {
+			for key, value := range shards[i].EntrySnapshot() {
+				if !yield(key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// EntryLocked returns an iterator over the entries in the map that holds the
+// read lock of each shard while the iteration is in it. The body of the loop
+// must not modify the map, or it may deadlock.
+//
+// Returns:
+//   - iter.Seq2[K, V]: An iterator over the entries in the map. Never returns
+//     nil.
+func (sm *ShardedMap[K, V]) EntryLocked() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if sm == nil {
+			return
+		}
+
+		shards := sm.all()
+
+		for i := range shards {
+			for key, value := range shards[i].EntryLocked() {
+				if !yield(key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GetMap returns a copy of the entries in the map. While the map is modified
+// concurrently, the shards are copied one after the other.
+//
+// Returns:
+//   - map[K]V: The copy. Nil if the receiver is nil.
+func (sm *ShardedMap[K, V]) GetMap() map[K]V {
+	if sm == nil {
+		return nil
+	}
+
+	m := make(map[K]V)
+
+	for key, value := range sm.Entry() {
+		m[key] = value
+	}
+
+	return m
+}
+
+// Reset removes all elements from the map.
+func (sm *ShardedMap[K, V]) Reset() {
+	if sm == nil {
+		return
+	}
+
+	shards := sm.all()
+
+	for i := range shards {
+		shards[i].Reset()
+	}
+}
+
+// LoadOrStore is the same as Map.LoadOrStore.
+func (sm *ShardedMap[K, V]) LoadOrStore(key K, val V) (V, bool) {
+	if sm == nil {
+		return *new(V), false
+	}
+
+	return sm.shardOf(key).LoadOrStore(key, val)
+}
+
+// SetIfAbsent is the same as Map.SetIfAbsent.
+func (sm *ShardedMap[K, V]) SetIfAbsent(key K, val V) bool {
+	if sm == nil {
+		return false
+	}
+
+	return sm.shardOf(key).SetIfAbsent(key, val)
+}
+
+// LoadAndDelete is the same as Map.LoadAndDelete.
+func (sm *ShardedMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	if sm == nil {
+		return *new(V), false
+	}
+
+	return sm.shardOf(key).LoadAndDelete(key)
+}
+
+// CompareAndSwap is the same as Map.CompareAndSwap.
+func (sm *ShardedMap[K, V]) CompareAndSwap(key K, old, val V, eq func(a, b V) bool) bool {
+	if sm == nil {
+		return false
+	}
+
+	return sm.shardOf(key).CompareAndSwap(key, old, val, eq)
+}
+
+// Compute is the same as Map.Compute.
+func (sm *ShardedMap[K, V]) Compute(key K, fn func(old V, exists bool) (V, bool)) (V, bool) {
+	if sm == nil {
+		return *new(V), false
+	}
+
+	return sm.shardOf(key).Compute(key, fn)
+}
+
+// Update is the same as Map.Update.
+func (sm *ShardedMap[K, V]) Update(key K, fn func(old V) V) bool {
+	if sm == nil {
+		return false
+	}
+
+	return sm.shardOf(key).Update(key, fn)
+}
+
+// DeleteIf is the same as Map.DeleteIf. The shards are visited one after the
+// other, so the removal is only atomic per shard.
+func (sm *ShardedMap[K, V]) DeleteIf(pred func(key K, val V) bool) int {
+	if sm == nil {
+		return 0
+	}
+
+	var n int
+
+	shards := sm.all()
+
+	for i := range shards {
+		n += shards[i].DeleteIf(pred)
+	}
+
+	return n
+}
@@ -0,0 +1,202 @@
+package rws
+
+import (
+	"maps"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestShardedMap(t *testing.T) {
+	const (
+		Writers int = 16
+		PerW    int = 1000
+	)
+
+	sm := NewShardedMap[string, int](5, nil)
+
+	n := len(sm.shards)
+	if n != 8 {
+		t.Fatalf("expected 8 shards, got %d", n)
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(Writers)
+
+	for w := range Writers {
+		go func() {
+			defer wg.Done()
+
+			for i := range PerW {
+				key := strconv.Itoa(w*PerW + i)
+
+				_ = sm.Set(key, i)
+
+				_, ok := sm.Get(key)
+				if !ok {
+					t.Errorf("expected %q to be set", key)
+					return
+				}
+
+				if i%2 == 1 {
+					sm.Delete(key)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	n = sm.Len()
+	if n != Writers*PerW/2 {
+		t.Fatalf("expected %d entries, got %d", Writers*PerW/2, n)
+	}
+
+	got := sm.GetMap()
+
+	for key, value := range sm.Entry() {
+		if got[key] != value || value%2 != 0 {
+			t.Fatalf("unexpected entry %q: %d", key, value)
+		}
+	}
+
+	var zero ShardedMap[int, int]
+
+	_ = zero.Set(1, 2)
+
+	if !maps.Equal(zero.GetMap(), map[int]int{1: 2}) {
+		t.Fatalf("expected map[1:2], got %v", zero.GetMap())
+	}
+
+	zero.Reset()
+
+	if zero.Len() != 0 {
+		t.Fatalf("expected the map to be empty")
+	}
+}
+
+func TestShardedMapHash(t *testing.T) {
+	type id string
+
+	ids := NewShardedMap[id, int](4, nil)
+
+	_ = ids.Set("a", 1)
+
+	v, ok := ids.Get("a")
+	if !ok || v != 1 {
+		t.Fatalf("expected 1, got %d", v)
+	}
+
+	var zero ShardedMap[float64, int]
+
+	_ = zero.Set(0.0, 1)
+	_ = zero.Set(math.Copysign(0, -1), 2)
+
+	n := zero.Len()
+	if n != 1 {
+		t.Fatalf("expected 0.0 and -0.0 to be the same key, got %d entries", n)
+	} else if len(zero.shards) != 1 {
+		t.Fatalf("expected float keys without a hash function to use 1 shard, got %d", len(zero.shards))
+	}
+
+	floats := NewShardedMap[float64, int](4, func(key float64) uint64 {
+		if key == 0 {
+			return 0
+		}
+
+		return math.Float64bits(key)
+	})
+
+	_ = floats.Set(0.0, 1)
+	_ = floats.Set(math.Copysign(0, -1), 2)
+
+	n = floats.Len()
+	if n != 1 {
+		t.Fatalf("expected 0.0 and -0.0 to be the same key, got %d entries", n)
+	}
+}
+
+// store is the API shared by the maps of the benchmarks.
+type store interface {
+	Load(key string) (int, bool)
+	Store(key string, value int)
+}
+
+// rwsMap adapts a Map to the store interface.
+type rwsMap struct{ m Map[string, int] }
+
+func (r *rwsMap) Load(key string) (int, bool) { return r.m.Get(key) }
+func (r *rwsMap) Store(key string, value int) { _ = r.m.Set(key, value) }
+
+// shardedMap adapts a ShardedMap to the store interface.
+type shardedMap struct{ m ShardedMap[string, int] }
+
+func (s *shardedMap) Load(key string) (int, bool) { return s.m.Get(key) }
+func (s *shardedMap) Store(key string, value int) { _ = s.m.Set(key, value) }
+
+// syncMap adapts a sync.Map to the store interface.
+type syncMap struct{ m sync.Map }
+
+func (s *syncMap) Load(key string) (int, bool) {
+	v, ok := s.m.Load(key)
+	if !ok {
+		return 0, false
+	}
+
+	return v.(int), true
+}
+
+func (s *syncMap) Store(key string, value int) { s.m.Store(key, value) }
+
+// benchmarkMixed runs a workload where one operation out of writeEvery is a
+// write, over 1024 session keys. Every goroutine starts at its own offset so
+// that they do not hit the same keys in lockstep.
+func benchmarkMixed(b *testing.B, m store, writeEvery int) {
+	const Keys int = 1024
+
+	keys := make([]string, Keys)
+
+	for i := range keys {
+		keys[i] = "session-" + strconv.Itoa(i)
+		m.Store(keys[i], i)
+	}
+
+	var offset atomic.Int64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		start := int(offset.Add(1)) * 7919
+
+		for i := 0; pb.Next(); i++ {
+			key := keys[(start+i*31)%Keys]
+
+			if i%writeEvery == 0 {
+				m.Store(key, i)
+			} else {
+				_, _ = m.Load(key)
+			}
+		}
+	})
+}
+
+func BenchmarkMaps(b *testing.B) {
+	workloads := []struct {
+		name       string
+		writeEvery int
+	}{
+		{"write-heavy", 1},
+		{"mixed", 2},
+		{"read-heavy", 10},
+	}
+
+	for _, w := range workloads {
+		b.Run(w.name+"/Map", func(b *testing.B) { benchmarkMixed(b, new(rwsMap), w.writeEvery) })
+		b.Run(w.name+"/ShardedMap", func(b *testing.B) { benchmarkMixed(b, new(shardedMap), w.writeEvery) })
+		b.Run(w.name+"/sync.Map", func(b *testing.B) { benchmarkMixed(b, new(syncMap), w.writeEvery) })
+	}
+}
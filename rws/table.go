@@ -216,21 +216,56 @@ func (t *Table[T]) SetCellAt(cell T, x, y int) {
 	t.table[y][x] = cell
 }
 
-// Row returns an iterator over the rows in the table.
+// Row returns an iterator over the rows in the table. It is the same as
+// RowSnapshot.
 //
 // Returns:
 //   - iter.Seq2[int, []T]: An iterator over the rows in the table. Never returns nil.
 func (t *Table[T]) Row() iter.Seq2[int, []T] {
-	if t == nil {
-		return nil
-	}
+	return t.RowSnapshot()
+}
 
-	t.mu.Lock()
-	defer t.mu.Unlock()
+// RowSnapshot returns an iterator over a copy of the rows in the table, taken
+// when the iteration starts. The table can be modified while iterating.
+//
+// Returns:
+//   - iter.Seq2[int, []T]: An iterator over the rows in the table. Never returns nil.
+func (t *Table[T]) RowSnapshot() iter.Seq2[int, []T] {
+	return func(yield func(int, []T) bool) {
+		if t == nil {
+			return
+		}
+
+		for i, row := range t.snapshot().Rows {
+			if !yield(i, row) {
+				return
+			}
+		}
+	}
+}
 
+// RowLocked returns an iterator over the rows in the table that holds the read
+// lock for the whole iteration. Each row is copied when it is reached, so
+// modifying it does not modify the table. The other goroutines cannot modify
+// the table until the loop ends; and the body of the loop must not modify it
+// either, or it deadlocks.
+//
+// Returns:
+//   - iter.Seq2[int, []T]: An iterator over the rows in the table. Never returns nil.
+func (t *Table[T]) RowLocked() iter.Seq2[int, []T] {
 	return func(yield func(int, []T) bool) {
+		if t == nil {
+			return
+		}
+
+		t.mu.RLock()
+		defer t.mu.RUnlock()
+
 		for i := 0; i < t.height; i++ {
-			if !yield(i, t.table[i]) {
+			row := make([]T, t.width)
+			copy(row, t.table[i])
+
+			if !yield(i, row) {
 				return
 			}
 		}
@@ -0,0 +1,494 @@
+package rws
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+	sbj "github.com/PlayerR9/go-safe/subject"
+)
+
+// Eviction is the notification that an entry of a TTLMap expired and was
+// removed.
+type Eviction[K comparable, V any] struct {
+	// Key is the key of the entry.
+	Key K
+
+	// Value is the value of the entry.
+	Value V
+
+	// ExpiredAt is the time at which the entry expired.
+	ExpiredAt time.Time
+}
+
+// ttlEntry is an entry of a TTLMap.
+type ttlEntry[V any] struct {
+	// value is the value of the entry.
+	value V
+
+	// ttl is the time-to-live of the entry. Not positive if it never expires.
+	ttl time.Duration
+
+	// expiresAt is the time at which the entry expires. The zero time if it
+	// never expires.
+	expiresAt time.Time
+}
+
+// expired checks whether the entry is expired.
+//
+// Parameters:
+//   - now: The current time.
+//
+// Returns:
+//   - bool: True if the entry is expired, false otherwise.
+func (e ttlEntry[V]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// ttlConfig is the configuration of a TTLMap.
+type ttlConfig struct {
+	// clock is the clock of the map.
+	clock common.Clock
+
+	// refresh is true if reading an entry renews its time-to-live.
+	refresh bool
+
+	// interval is the interval at which the expired entries are removed in the
+	// background. Not positive if they are only removed lazily.
+	interval time.Duration
+}
+
+// TTLOption is an option of NewTTLMap.
+type TTLOption func(cfg *ttlConfig)
+
+// WithClock sets the clock of the map. It is meant to inject a
+// common.ManualClock in tests.
+//
+// Parameters:
+//   - clock: The clock to use. If nil, common.RealClock is used.
+//
+// Returns:
+//   - TTLOption: The option. Never returns nil.
+func WithClock(clock common.Clock) TTLOption {
+	return func(cfg *ttlConfig) {
+		cfg.clock = clock
+	}
+}
+
+// WithRefreshOnRead makes reading an entry with Get or GetWithTTL renew its
+// time-to-live.
+//
+// Returns:
+//   - TTLOption: The option. Never returns nil.
+func WithRefreshOnRead() TTLOption {
+	return func(cfg *ttlConfig) {
+		cfg.refresh = true
+	}
+}
+
+// WithJanitor makes the map remove its expired entries in the background.
+// Otherwise, an expired entry is only removed when it is read or when Purge is
+// called.
+//
+// Parameters:
+//   - interval: The interval between two removals. If it is not positive, there
+//     is no background removal.
+//
+// Returns:
+//   - TTLOption: The option. Never returns nil.
+func WithJanitor(interval time.Duration) TTLOption {
+	return func(cfg *ttlConfig) {
+		cfg.interval = interval
+	}
+}
+
+// TTLMap is a thread-safe map whose entries expire after their time-to-live.
+// An expired entry is never returned; it is removed either lazily, when it is
+// read, or in the background by a janitor, and its observers are notified of
+// the eviction.
+//
+// A TTLMap must be created with NewTTLMap and closed with Close.
+type TTLMap[K comparable, V any] struct {
+	// entries are the entries of the map.
+	entries Map[K, ttlEntry[V]]
+
+	// ttl is the default time-to-live of the entries.
+	ttl time.Duration
+
+	// cfg is the configuration of the map.
+	cfg ttlConfig
+
+	// observers are the observers of the evictions.
+	observers []sbj.Observer[Eviction[K, V]]
+
+	// closed is true once the map is closed. It is guarded by omu.
+	closed bool
+
+	// omu is the mutex that synchronizes the observers.
+	omu sync.RWMutex
+
+	// stop stops the janitor.
+	stop chan struct{}
+
+	// done is closed once the janitor has stopped.
+	done chan struct{}
+
+	// closeOnce makes Close idempotent.
+	closeOnce sync.Once
+}
+
+// NewTTLMap creates a new TTLMap.
+//
+// Parameters:
+//   - ttl: The default time-to-live of the entries. If it is not positive, the
+//     entries set with Set never expire.
+//   - opts: The options of the map.
+//
+// Returns:
+//   - *TTLMap[K, V]: The new map. Never returns nil.
+func NewTTLMap[K comparable, V any](ttl time.Duration, opts ...TTLOption) *TTLMap[K, V] {
+	var cfg ttlConfig
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	if cfg.clock == nil {
+		cfg.clock = common.RealClock
+	}
+
+	tm := &TTLMap[K, V]{
+		ttl:  ttl,
+		cfg:  cfg,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	if cfg.interval <= 0 {
+		close(tm.done)
+		return tm
+	}
+
+	// The first timer is created here so that a manual clock advanced right
+	// after the creation of the map fires it.
+	timer := cfg.clock.NewTimer(cfg.interval)
+
+	go tm.janitor(timer)
+
+	return tm
+}
+
+// janitor removes the expired entries at every interval until the map is
+// closed.
+//
+// It must be run in a separate goroutine to avoid blocking the main thread.
+//
+// Parameters:
+//   - timer: The timer of the first removal.
+func (tm *TTLMap[K, V]) janitor(timer common.Timer) {
+	defer close(tm.done)
+
+	for {
+		select {
+		case <-timer.C():
+			_ = tm.Purge()
+
+			timer = tm.cfg.clock.NewTimer(tm.cfg.interval)
+		case <-tm.stop:
+			_ = timer.Stop()
+			return
+		}
+	}
+}
+
+// Close stops the janitor and cleans up the observers; no observer can be
+// added afterwards. The map can still be used, but its expired entries are
+// then only removed lazily.
+func (tm *TTLMap[K, V]) Close() {
+	if tm == nil {
+		return
+	}
+
+	tm.closeOnce.Do(func() {
+		close(tm.stop)
+		<-tm.done
+
+		tm.omu.Lock()
+
+		observers := tm.observers
+
+		tm.observers = nil
+		tm.closed = true
+
+		tm.omu.Unlock()
+
+		for _, o := range observers {
+			o.Cleanup()
+		}
+	})
+}
+
+// Observe adds an observer of the evictions. It is notified, in the goroutine
+// that removed the entry and after its removal, of every entry that expires.
+// The observer may call the methods of the map. Does nothing if the observer
+// is nil.
+//
+// Parameters:
+//   - o: The observer.
+//
+// Returns:
+//   - error: An error if the observer could not be added.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrMapClosed: If the map is closed. The observer is cleaned up right
+//     away.
+func (tm *TTLMap[K, V]) Observe(o sbj.Observer[Eviction[K, V]]) error {
+	if o == nil {
+		return nil
+	} else if tm == nil {
+		return common.ErrNilReceiver
+	}
+
+	tm.omu.Lock()
+
+	if tm.closed {
+		tm.omu.Unlock()
+
+		o.Cleanup()
+
+		return ErrMapClosed
+	}
+
+	tm.observers = append(tm.observers, o)
+
+	tm.omu.Unlock()
+
+	return nil
+}
+
+// evict notifies the observers of evictions.
+//
+// Parameters:
+//   - evictions: The evictions.
+func (tm *TTLMap[K, V]) evict(evictions ...Eviction[K, V]) {
+	if len(evictions) == 0 {
+		return
+	}
+
+	tm.omu.RLock()
+	observers := slices.Clone(tm.observers)
+	tm.omu.RUnlock()
+
+	for _, ev := range evictions {
+		for _, o := range observers {
+			_ = o.Notify(ev)
+		}
+	}
+}
+
+// entryOf creates an entry.
+//
+// Parameters:
+//   - val: The value of the entry.
+//   - ttl: The time-to-live of the entry.
+//   - now: The current time.
+//
+// Returns:
+//   - ttlEntry[V]: The entry.
+func entryOf[V any](val V, ttl time.Duration, now time.Time) ttlEntry[V] {
+	e := ttlEntry[V]{
+		value: val,
+		ttl:   ttl,
+	}
+
+	if ttl > 0 {
+		e.expiresAt = now.Add(ttl)
+	}
+
+	return e
+}
+
+// Set sets a value in the map with the default time-to-live.
+//
+// Parameters:
+//   - key: The key to set the value.
+//   - val: The value to set.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+func (tm *TTLMap[K, V]) Set(key K, val V) error {
+	if tm == nil {
+		return common.ErrNilReceiver
+	}
+
+	return tm.SetWithTTL(key, val, tm.ttl)
+}
+
+// SetWithTTL sets a value in the map with its own time-to-live.
+//
+// Parameters:
+//   - key: The key to set the value.
+//   - val: The value to set.
+//   - ttl: The time-to-live of the entry. If it is not positive, the entry
+//     never expires.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+func (tm *TTLMap[K, V]) SetWithTTL(key K, val V, ttl time.Duration) error {
+	if tm == nil {
+		return common.ErrNilReceiver
+	}
+
+	return tm.entries.Set(key, entryOf(val, ttl, tm.cfg.clock.Now()))
+}
+
+// load reads an entry, removing it if it is expired and renewing it if the map
+// refreshes its entries on read.
+//
+// Parameters:
+//   - key: The key of the entry.
+//
+// Returns:
+//   - ttlEntry[V]: The entry.
+//   - bool: True if the entry exists and is not expired, false otherwise.
+func (tm *TTLMap[K, V]) load(key K) (ttlEntry[V], bool) {
+	now := tm.cfg.clock.Now()
+
+	e, ok := tm.entries.Get(key)
+	if !ok {
+		return ttlEntry[V]{}, false
+	} else if !e.expired(now) && (!tm.cfg.refresh || e.expiresAt.IsZero()) {
+		return e, true
+	}
+
+	var evicted *Eviction[K, V]
+
+	e, ok = tm.entries.Compute(key, func(old ttlEntry[V], exists bool) (ttlEntry[V], bool) {
+		if !exists {
+			return old, false
+		} else if old.expired(now) {
+			evicted = &Eviction[K, V]{
+				Key:       key,
+				Value:     old.value,
+				ExpiredAt: old.expiresAt,
+			}
+
+			return old, false
+		}
+
+		return entryOf(old.value, old.ttl, now), true
+	})
+
+	if evicted != nil {
+		tm.evict(*evicted)
+	}
+
+	return e, ok
+}
+
+// Get retrieves a value from the map. An expired entry is removed instead.
+//
+// Parameters:
+//   - key: The key to retrieve the value.
+//
+// Returns:
+//   - V: The value associated with the key.
+//   - bool: A boolean indicating if the key exists in the map and is not
+//     expired.
+func (tm *TTLMap[K, V]) Get(key K) (V, bool) {
+	if tm == nil {
+		return *new(V), false
+	}
+
+	e, ok := tm.load(key)
+
+	return e.value, ok
+}
+
+// GetWithTTL retrieves a value from the map together with its remaining
+// time-to-live. An expired entry is removed instead.
+//
+// Parameters:
+//   - key: The key to retrieve the value.
+//
+// Returns:
+//   - V: The value associated with the key.
+//   - time.Duration: The remaining time-to-live of the entry. 0 if it never
+//     expires.
+//   - bool: A boolean indicating if the key exists in the map and is not
+//     expired.
+func (tm *TTLMap[K, V]) GetWithTTL(key K) (V, time.Duration, bool) {
+	if tm == nil {
+		return *new(V), 0, false
+	}
+
+	e, ok := tm.load(key)
+	if !ok || e.expiresAt.IsZero() {
+		return e.value, 0, ok
+	}
+
+	return e.value, e.expiresAt.Sub(tm.cfg.clock.Now()), true
+}
+
+// Delete removes a key from the map. The observers are not notified. Does
+// nothing if the key does not exist in the map.
+//
+// Parameters:
+//   - key: The key to remove.
+func (tm *TTLMap[K, V]) Delete(key K) {
+	if tm == nil {
+		return
+	}
+
+	tm.entries.Delete(key)
+}
+
+// Len returns the number of entries in the map, including the expired entries
+// that were not removed yet.
+//
+// Returns:
+//   - int: The number of entries in the map. 0 if the receiver is nil.
+func (tm *TTLMap[K, V]) Len() int {
+	if tm == nil {
+		return 0
+	}
+
+	return tm.entries.Len()
+}
+
+// Purge removes the expired entries and notifies the observers of their
+// eviction.
+//
+// Returns:
+//   - int: The number of removed entries. 0 if the receiver is nil.
+func (tm *TTLMap[K, V]) Purge() int {
+	if tm == nil {
+		return 0
+	}
+
+	now := tm.cfg.clock.Now()
+
+	var evictions []Eviction[K, V]
+
+	n := tm.entries.DeleteIf(func(key K, e ttlEntry[V]) bool {
+		if !e.expired(now) {
+			return false
+		}
+
+		evictions = append(evictions, Eviction[K, V]{
+			Key:       key,
+			Value:     e.value,
+			ExpiredAt: e.expiresAt,
+		})
+
+		return true
+	})
+
+	tm.evict(evictions...)
+
+	return n
+}
@@ -0,0 +1,178 @@
+package rws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PlayerR9/go-safe/common"
+	sbj "github.com/PlayerR9/go-safe/subject"
+)
+
+func TestTTLMap(t *testing.T) {
+	clock := common.NewManualClock(time.Unix(0, 0))
+
+	tm := NewTTLMap[string, int](time.Second, WithClock(clock))
+	defer tm.Close()
+
+	var evicted []string
+
+	_ = tm.Observe(sbj.FromAction(func(ev Eviction[string, int]) error {
+		evicted = append(evicted, ev.Key)
+		return nil
+	}))
+
+	_ = tm.Set("a", 1)
+	_ = tm.SetWithTTL("b", 2, 3*time.Second)
+	_ = tm.SetWithTTL("c", 3, 0)
+
+	clock.Advance(500 * time.Millisecond)
+
+	v, ttl, ok := tm.GetWithTTL("a")
+	if !ok || v != 1 || ttl != 500*time.Millisecond {
+		t.Fatalf("expected a=1 with 500ms left, got %d with %v (%t)", v, ttl, ok)
+	}
+
+	clock.Advance(time.Second)
+
+	_, ok = tm.Get("a")
+	if ok {
+		t.Fatalf("expected a to be expired")
+	}
+
+	if tm.Len() != 2 || len(evicted) != 1 || evicted[0] != "a" {
+		t.Fatalf("expected a to be evicted lazily, got %v", evicted)
+	}
+
+	clock.Advance(2 * time.Second)
+
+	n := tm.Purge()
+	if n != 1 || len(evicted) != 2 || evicted[1] != "b" {
+		t.Fatalf("expected b to be purged, got %d (%v)", n, evicted)
+	}
+
+	v, ttl, ok = tm.GetWithTTL("c")
+	if !ok || v != 3 || ttl != 0 {
+		t.Fatalf("expected c=3 to never expire, got %d with %v (%t)", v, ttl, ok)
+	}
+}
+
+func TestTTLMapRefreshOnRead(t *testing.T) {
+	clock := common.NewManualClock(time.Unix(0, 0))
+
+	tm := NewTTLMap[string, int](time.Second, WithClock(clock), WithRefreshOnRead())
+	defer tm.Close()
+
+	_ = tm.Set("a", 1)
+
+	for range 5 {
+		clock.Advance(800 * time.Millisecond)
+
+		_, ok := tm.Get("a")
+		if !ok {
+			t.Fatalf("expected a to be refreshed on read")
+		}
+	}
+
+	clock.Advance(time.Second)
+
+	_, ok := tm.Get("a")
+	if ok {
+		t.Fatalf("expected a to be expired")
+	}
+}
+
+func TestTTLMapJanitor(t *testing.T) {
+	clock := common.NewManualClock(time.Unix(0, 0))
+
+	tm := NewTTLMap[string, int](time.Second, WithClock(clock), WithJanitor(time.Second))
+	defer tm.Close()
+
+	evicted := make(chan Eviction[string, int], 1)
+
+	_ = tm.Observe(sbj.FromAction(func(ev Eviction[string, int]) error {
+		evicted <- ev
+		return nil
+	}))
+
+	_ = tm.Set("a", 1)
+
+	clock.Advance(time.Second)
+
+	select {
+	case ev := <-evicted:
+		if ev.Key != "a" || ev.Value != 1 || !ev.ExpiredAt.Equal(time.Unix(1, 0)) {
+			t.Fatalf("unexpected eviction %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the janitor to evict a")
+	}
+
+	if tm.Len() != 0 {
+		t.Fatalf("expected the map to be empty")
+	}
+}
+
+// cleanupObserver is an observer that records whether it was cleaned up.
+type cleanupObserver struct {
+	notify  func(ev Eviction[string, int]) error
+	cleaned bool
+}
+
+func (o *cleanupObserver) Notify(ev Eviction[string, int]) error { return o.notify(ev) }
+func (o *cleanupObserver) Cleanup()                              { o.cleaned = true }
+
+func TestTTLMapObserveReentrant(t *testing.T) {
+	clock := common.NewManualClock(time.Unix(0, 0))
+
+	tm := NewTTLMap[string, int](time.Second, WithClock(clock))
+
+	var added int
+
+	o := &cleanupObserver{
+		notify: func(ev Eviction[string, int]) error {
+			added++
+
+			return tm.Observe(sbj.FromAction(func(ev Eviction[string, int]) error {
+				return nil
+			}))
+		},
+	}
+
+	_ = tm.Observe(o)
+	_ = tm.Set("a", 1)
+
+	clock.Advance(time.Second)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_, _ = tm.Get("a")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("an observer that calls Observe deadlocked the map")
+	}
+
+	if added != 1 {
+		t.Fatalf("expected the observer to be notified once, got %d", added)
+	}
+
+	tm.Close()
+
+	if !o.cleaned {
+		t.Fatalf("expected the observer to be cleaned up on close")
+	}
+
+	late := &cleanupObserver{}
+
+	err := tm.Observe(late)
+	if err != ErrMapClosed {
+		t.Fatalf("expected %v, got %v", ErrMapClosed, err)
+	} else if !late.cleaned {
+		t.Fatalf("expected the observer to be cleaned up right away")
+	}
+}
@@ -0,0 +1,192 @@
+package subject
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/PlayerR9/go-safe/common"
+)
+
+// coalescer delivers the values published to a Notifier to an asynchronous
+// observer. At most one goroutine runs per observer, and the values published
+// while the observer is busy are coalesced into the latest one.
+type coalescer[T any] struct {
+	// action is the action of the observer.
+	action Action[T]
+
+	// latest is the latest value not delivered yet.
+	latest T
+
+	// pending is true if latest was not delivered yet.
+	pending bool
+
+	// running is true while a goroutine delivers values.
+	running bool
+
+	// mu is the mutex that synchronizes the coalescer.
+	mu sync.Mutex
+}
+
+// push records a value and starts delivering it if no goroutine does.
+//
+// Parameters:
+//   - value: The value to deliver.
+func (c *coalescer[T]) push(value T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.latest = value
+	c.pending = true
+
+	if !c.running {
+		c.running = true
+
+		go c.run()
+	}
+}
+
+// run delivers the pending values until there is none.
+//
+// It must be run in a separate goroutine to avoid blocking the main thread.
+func (c *coalescer[T]) run() {
+	for {
+		c.mu.Lock()
+
+		if !c.pending {
+			c.running = false
+			c.mu.Unlock()
+
+			return
+		}
+
+		value := c.latest
+		c.latest = *new(T)
+		c.pending = false
+
+		c.mu.Unlock()
+
+		_ = c.action(value)
+	}
+}
+
+// Notifier notifies observers of the values published by its owner. Unlike
+// Subject, it neither stores the value nor spawns a goroutine per observer and
+// per change, and publishing costs a single atomic load when there are no
+// observers.
+//
+// Observers are either synchronous, called in order by Publish itself, or
+// coalesced, called from their own goroutine with the latest published value
+// and possibly skipping intermediate ones.
+//
+// An empty Notifier is created by using the `n := new(Notifier[T])`
+// constructor; its zero value is also ready to use.
+type Notifier[T any] struct {
+	// syncs are the synchronous observers. The slice is never modified in
+	// place so that Publish can use it without holding the lock.
+	syncs []Action[T]
+
+	// coalescers are the coalesced observers. The slice is never modified in
+	// place so that Publish can use it without holding the lock.
+	coalescers []*coalescer[T]
+
+	// count is the number of observers.
+	count atomic.Int32
+
+	// mu is the mutex that synchronizes the observers.
+	mu sync.Mutex
+}
+
+// Observe adds a synchronous observer. It is called by Publish, so it must be
+// cheap and must not call back into the owner of the Notifier if the owner
+// publishes while holding a lock. Its errors are ignored. Does nothing if the
+// action is nil.
+//
+// Parameters:
+//   - action: The action to perform on every published value.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (n *Notifier[T]) Observe(action Action[T]) error {
+	if action == nil {
+		return nil
+	} else if n == nil {
+		return common.ErrNilReceiver
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.syncs = append(slices.Clip(n.syncs), action)
+	n.count.Add(1)
+
+	return nil
+}
+
+// ObserveCoalesced adds a coalesced observer. It is called from its own
+// goroutine with the latest published value; values published while it runs
+// are coalesced, so it may not see every value but always sees the last one.
+// Its errors are ignored. Does nothing if the action is nil.
+//
+// Parameters:
+//   - action: The action to perform on the published values.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (n *Notifier[T]) ObserveCoalesced(action Action[T]) error {
+	if action == nil {
+		return nil
+	} else if n == nil {
+		return common.ErrNilReceiver
+	}
+
+	c := &coalescer[T]{
+		action: action,
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.coalescers = append(slices.Clip(n.coalescers), c)
+	n.count.Add(1)
+
+	return nil
+}
+
+// HasObservers checks whether the Notifier has observers.
+//
+// Returns:
+//   - bool: True if there is at least one observer, false otherwise.
+func (n *Notifier[T]) HasObservers() bool {
+	return n != nil && n.count.Load() > 0
+}
+
+// Publish notifies the observers of a value. The synchronous observers are
+// called before Publish returns.
+//
+// Parameters:
+//   - value: The value to publish.
+func (n *Notifier[T]) Publish(value T) {
+	if !n.HasObservers() {
+		return
+	}
+
+	n.mu.Lock()
+	syncs := n.syncs
+	coalescers := n.coalescers
+	n.mu.Unlock()
+
+	for _, action := range syncs {
+		_ = action(value)
+	}
+
+	for _, c := range coalescers {
+		c.push(value)
+	}
+}